@@ -0,0 +1,87 @@
+package asnlookup
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidASN is returned when ASN is badly formatted or is larger than
+// 4294967295
+var ErrInvalidASN = errors.New("Invalid ASN")
+
+// ASN is 32 bit autonomous system number
+type ASN uint32
+
+// ASNotation is notation ASN is printed in (RFC 5396)
+type ASNotation string
+
+// Notations supported by ASN.Format
+const (
+	// ASPlain prints ASN as one decimal number, e.g. 65546
+	ASPlain ASNotation = "asplain"
+
+	// ASDot prints ASNs below 65536 as asplain and larger ASNs as
+	// "<high 16 bits>.<low 16 bits>", e.g. 1.10
+	ASDot ASNotation = "asdot"
+
+	// ASDotPlus prints all ASNs as "<high 16 bits>.<low 16 bits>",
+	// e.g. 0.100 and 1.10
+	ASDotPlus ASNotation = "asdot+"
+)
+
+// ParseASN parses ASN in asplain ("65546"), asdot or asdot+ ("1.10")
+// notation, optionally prefixed with "AS" in any case ("AS65546",
+// "as1.10"). It returns ErrInvalidASN if "s" is not a valid 32 bit ASN.
+func ParseASN(s string) (ASN, error) {
+	if len(s) > 2 && strings.EqualFold(s[:2], "AS") {
+		s = s[2:]
+	}
+
+	dot := strings.Index(s, ".")
+	if dot < 0 {
+		asn, err := parseDecimal(s, 32)
+		return ASN(asn), err
+	}
+
+	high, err := parseDecimal(s[:dot], 16)
+	if err != nil {
+		return 0, err
+	}
+
+	low, err := parseDecimal(s[dot+1:], 16)
+	if err != nil {
+		return 0, err
+	}
+
+	return ASN(high<<16 | low), nil
+}
+
+// parseDecimal parses unsigned decimal number of at most "bitSize" bits.
+// Unlike strconv.ParseUint it does not accept signs or underscores.
+func parseDecimal(s string, bitSize int) (uint64, error) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, ErrInvalidASN
+	}
+
+	n, err := strconv.ParseUint(s, 10, bitSize)
+	if err != nil {
+		return 0, ErrInvalidASN
+	}
+
+	return n, nil
+}
+
+// String returns ASN in asplain notation
+func (a ASN) String() string {
+	return a.Format(ASPlain)
+}
+
+// Format returns ASN in "notation". Unknown notation is treated as ASPlain.
+func (a ASN) Format(notation ASNotation) string {
+	if notation == ASDotPlus || (notation == ASDot && a > 0xffff) {
+		return strconv.FormatUint(uint64(a>>16), 10) + "." + strconv.FormatUint(uint64(a&0xffff), 10)
+	}
+
+	return strconv.FormatUint(uint64(a), 10)
+}
@@ -0,0 +1,162 @@
+package asnlookup
+
+import (
+	"testing"
+)
+
+func TestParseASN(t *testing.T) {
+	testCases := []struct {
+		name string
+		asn  string
+		want ASN
+		err  error
+	}{
+		{
+			name: "Parse Asplain",
+			asn:  "13335",
+			want: 13335,
+			err:  nil,
+		},
+		{
+			name: "Parse Asplain With AS Prefix",
+			asn:  "AS13335",
+			want: 13335,
+			err:  nil,
+		},
+		{
+			name: "Parse Asplain With Lower Case AS Prefix",
+			asn:  "as13335",
+			want: 13335,
+			err:  nil,
+		},
+		{
+			name: "Parse Asdot",
+			asn:  "1.10",
+			want: 65546,
+			err:  nil,
+		},
+		{
+			name: "Parse Asdot+ Below 65536",
+			asn:  "AS0.100",
+			want: 100,
+			err:  nil,
+		},
+		{
+			name: "Parse Largest ASN",
+			asn:  "4294967295",
+			want: 4294967295,
+			err:  nil,
+		},
+		{
+			name: "Parse Largest Asdot ASN",
+			asn:  "65535.65535",
+			want: 4294967295,
+			err:  nil,
+		},
+		{
+			name: "Parse ASN Above 32 Bits",
+			asn:  "4294967296",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+		{
+			name: "Parse Asdot Part Above 16 Bits",
+			asn:  "65536.1",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+		{
+			name: "Parse Negative ASN",
+			asn:  "-1",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+		{
+			name: "Parse ASN With Sign",
+			asn:  "+100",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+		{
+			name: "Parse Incomplete Asdot",
+			asn:  "1.",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+		{
+			name: "Parse AS Prefix Only",
+			asn:  "AS",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+		{
+			name: "Parse Empty ASN",
+			asn:  "",
+			want: 0,
+			err:  ErrInvalidASN,
+		},
+	}
+
+	for _, testCase := range testCases {
+		got, err := ParseASN(testCase.asn)
+		if err != testCase.err {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+		}
+
+		if got != testCase.want {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestASNFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		asn      ASN
+		notation ASNotation
+		want     string
+	}{
+		{
+			name:     "Format Asplain",
+			asn:      65546,
+			notation: ASPlain,
+			want:     "65546",
+		},
+		{
+			name:     "Format Asdot Below 65536",
+			asn:      100,
+			notation: ASDot,
+			want:     "100",
+		},
+		{
+			name:     "Format Asdot",
+			asn:      65546,
+			notation: ASDot,
+			want:     "1.10",
+		},
+		{
+			name:     "Format Asdot+ Below 65536",
+			asn:      100,
+			notation: ASDotPlus,
+			want:     "0.100",
+		},
+		{
+			name:     "Format Largest ASN As Asdot+",
+			asn:      4294967295,
+			notation: ASDotPlus,
+			want:     "65535.65535",
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := testCase.asn.Format(testCase.notation)
+		if got != testCase.want {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+
+		parsed, err := ParseASN(got)
+		if err != nil || parsed != testCase.asn {
+			t.Fatalf("%s: parsed ASN does not match: got %v, want %v", testCase.name, parsed, testCase.asn)
+		}
+	}
+}
@@ -3,14 +3,60 @@ package asnlookup
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 // NodeInfo stores subnet, Cidr and Asn information.
-// This structure is agnostic to IPv4 or IPv6 address
+// This structure is agnostic to IPv4 or IPv6 address.
+// When origin of the route is an AS_SET, ASSet holds all members of
+// the set and Asn holds its first member.
 type NodeInfo struct {
 	Subnet string
 	Cidr   int
-	Asn    int
+	Asn    ASN
+	ASSet  []ASN
+}
+
+// newNodeInfo creates NodeInfo stored in trie node for "ip"
+func newNodeInfo(ip IPAddress) NodeInfo {
+	info := NodeInfo{
+		Subnet: ip.GetString(),
+		Cidr:   ip.GetCidrLen(),
+		Asn:    ASN(ip.GetAsn()),
+	}
+
+	for _, asn := range ip.GetASSet() {
+		info.ASSet = append(info.ASSet, ASN(asn))
+	}
+
+	return info
+}
+
+// Prefix returns prefix of the route in CIDR format (8.8.8.0/24)
+func (n NodeInfo) Prefix() string {
+	return n.Subnet + "/" + strconv.Itoa(n.Cidr)
+}
+
+// Origin returns origin of the route as text in asplain notation. It is
+// the ASN, or members of AS_SET in "{1,2,3}" form.
+func (n NodeInfo) Origin() string {
+	return n.OriginNotation(ASPlain)
+}
+
+// OriginNotation returns origin of the route as text with ASNs printed
+// in "notation"
+func (n NodeInfo) OriginNotation(notation ASNotation) string {
+	if len(n.ASSet) == 0 {
+		return n.Asn.Format(notation)
+	}
+
+	members := make([]string, 0, len(n.ASSet))
+	for _, asn := range n.ASSet {
+		members = append(members, asn.Format(notation))
+	}
+
+	return "{" + strings.Join(members, ",") + "}"
 }
 
 // NodeInfoList stores a list of NodeInfo in a given trie node
@@ -23,12 +69,14 @@ func (n NodeInfoList) Len() int {
 	return len(n)
 }
 
-// Less implements Less() method for sort interface
+// Less implements Less() method for sort interface. Longer Cidr comes
+// first. Entries with same Cidr are ordered by origin ASN, see
+// compareOrigin, so order does not depend on order of table lines.
 func (n NodeInfoList) Less(i, j int) bool {
-	if n[i].Cidr > n[j].Cidr {
-		return true
+	if n[i].Cidr != n[j].Cidr {
+		return n[i].Cidr > n[j].Cidr
 	}
-	return false
+	return compareOrigin(n[i], n[j]) < 0
 }
 
 // Swap implements Swap() method for sort interface
@@ -44,19 +92,32 @@ type Node struct {
 }
 
 // Trie struct holds information about trie.
-// Currently it only holds root of trie. It can be
-// enhanced to store size & content info (IPv4 or IPv6)
+// IPv4 and IPv6 addresses are stored under separate roots, so one
+// trie can hold both address types and answer lookups for either.
+// Policy decides how routes with same prefix are stored.
 type Trie struct {
-	Root *Node
+	Root4  *Node
+	Root6  *Node
+	Policy InsertPolicy
 }
 
 // NewTrie creates a Trie and returns its pointer
 func NewTrie() *Trie {
 	return &Trie{
-		Root: NewNode(),
+		Root4: NewNode(),
+		Root6: NewNode(),
 	}
 }
 
+// root returns trie root for address type of "ip"
+func (t *Trie) root(ip IPAddress) *Node {
+	if ip.GetNumBitsInAddress() == 32 {
+		return t.Root4
+	}
+
+	return t.Root6
+}
+
 // NewNode creates a new trie node
 func NewNode() *Node {
 	return &Node{
@@ -67,12 +128,33 @@ func NewNode() *Node {
 
 }
 
+// Compile time check to ensure Trie satisfies RouteTrie interface
+var _ RouteTrie = &Trie{}
+
+// Insert adds "ip" into trie "t". Input "ip" can either be IPv4 or IPv6 address.
+func Insert(t RouteTrie, ip IPAddress) {
+	t.Insert(ip)
+}
+
 // Insert adds a node into the trie. Input "ip" can either be IPv4 or IPv6 address.
 // Trie is agnostic to IP address type as it works on 0s and 1s.
 // IPv4 trie can have maximum 32 lookups. IPv6 trie can have 128 lookups.
-func Insert(t *Trie, ip IPAddress) {
+// Routes with same prefix are stored according to t.Policy.
+func (t *Trie) Insert(ip IPAddress) (bool, NodeInfoList) {
+	return t.insert(ip, t.Policy)
+}
+
+// Update makes "ip" the only route with its prefix, whatever the Policy
+// of the trie is. It returns routes with same prefix it replaced.
+func (t *Trie) Update(ip IPAddress) NodeInfoList {
+	_, replaced := t.insert(ip, InsertReplace)
+	return replaced
+}
+
+// insert stores "ip" in the trie according to "policy"
+func (t *Trie) insert(ip IPAddress, policy InsertPolicy) (bool, NodeInfoList) {
 	// Safe to ignore error below as key will already be sanitized by this time
-	root := t.Root
+	root := t.root(ip)
 
 	// Get the Cidr prefix length and iterate over bits starting with highest
 	// order bit.
@@ -99,18 +181,70 @@ func Insert(t *Trie, ip IPAddress) {
 
 	// We are done interating over all bits of Cidr prefix. Store information in NodeList
 	// for current trie node
-	root.Info = append(root.Info, NodeInfo{ip.GetString(), ip.GetCidrLen(), ip.GetAsn()})
-	return
+	var stored bool
+	var replaced NodeInfoList
+	root.Info, stored, replaced = insertInfo(root.Info, newNodeInfo(ip), policy)
+	return stored, replaced
+}
+
+// Delete removes routes with prefix of "ip" and origin "asn" (first member
+// for AS_SET origin) from the trie. Nodes left without routes and children
+// are removed back toward the root, so the trie is same as if the routes
+// were never inserted. It returns removed routes.
+func (t *Trie) Delete(ip IPAddress, asn ASN) NodeInfoList {
+	// Keep nodes on the path to the prefix to prune them afterwards
+	path := []*Node{t.root(ip)}
+	node := path[0]
+	for i := 1; i <= ip.GetCidrLen(); i++ {
+		if ip.GetNthHighestBit(uint8(i)) == 0 {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+
+		if node == nil {
+			return nil
+		}
+		path = append(path, node)
+	}
+
+	var removed NodeInfoList
+	if node.Info, removed = removeInfo(node.Info, asn); len(removed) == 0 {
+		return nil
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		node = path[i]
+		if len(node.Info) > 0 || node.Left != nil || node.Right != nil {
+			break
+		}
+
+		if parent := path[i-1]; parent.Left == node {
+			parent.Left = nil
+		} else {
+			parent.Right = nil
+		}
+	}
+
+	return removed
 }
 
 // Find walks through the bits of target IP address and returns NodeInfoList
 // with matching trie nodes for target IP address
 func Find(cfg *Config) NodeInfoList {
-	infoList := NodeInfoList{}
-	root := cfg.trie.Root
+	return cfg.trie.Find(cfg.IPToFind)
+}
+
+// Find walks through the prefix bits of "ip" and returns NodeInfoList
+// sorted by Cidr length with matching trie nodes. These are the routes
+// which cover "ip", including route with same prefix.
+func (t *Trie) Find(ip IPAddress) NodeInfoList {
+	root := t.root(ip)
 
-	for i := 1; i <= cfg.IPToFind.GetNumBitsInAddress(); i++ {
-		child := cfg.IPToFind.GetNthHighestBit(uint8(i))
+	// Root holds /0 routes, which match every address
+	infoList := append(NodeInfoList{}, root.Info...)
+	for i := 1; i <= ip.GetCidrLen(); i++ {
+		child := ip.GetNthHighestBit(uint8(i))
 		if child == 0 && root.Left != nil {
 			// Left child matches with target IP. Store it in infoList
 			if len(root.Left.Info) > 0 {
@@ -134,11 +268,76 @@ func Find(cfg *Config) NodeInfoList {
 	return infoList
 }
 
+// LongestMatch returns the most specific route which covers "ip", the
+// first one in NodeInfoList order if several routes have same prefix. It returns false
+// if no route covers "ip". Unlike Find it does not allocate.
+func (t *Trie) LongestMatch(ip IPAddress) (NodeInfo, bool) {
+	root := t.root(ip)
+	best := root
+	for i := 1; i <= ip.GetCidrLen(); i++ {
+		if ip.GetNthHighestBit(uint8(i)) == 0 {
+			root = root.Left
+		} else {
+			root = root.Right
+		}
+
+		if root == nil {
+			break
+		}
+		if len(root.Info) > 0 {
+			best = root
+		}
+	}
+
+	if len(best.Info) == 0 {
+		return NodeInfo{}, false
+	}
+	return best.Info[0], true
+}
+
+// FindCovered returns routes which are more specific than "ip" and lie
+// inside its prefix. Routes are in address order, shorter prefix first
+// for same address.
+func (t *Trie) FindCovered(ip IPAddress) NodeInfoList {
+	infoList := NodeInfoList{}
+	root := t.root(ip)
+
+	// Walk down to the node of "ip" prefix
+	for i := 1; i <= ip.GetCidrLen(); i++ {
+		if ip.GetNthHighestBit(uint8(i)) == 0 {
+			root = root.Left
+		} else {
+			root = root.Right
+		}
+
+		if root == nil {
+			return infoList
+		}
+	}
+
+	infoList = root.Left.appendSubtree(infoList)
+	infoList = root.Right.appendSubtree(infoList)
+	return infoList
+}
+
+// appendSubtree appends Info of "n" and all nodes below it to "infoList"
+// in pre-order, which is address order
+func (n *Node) appendSubtree(infoList NodeInfoList) NodeInfoList {
+	if n == nil {
+		return infoList
+	}
+
+	infoList = append(infoList, n.Info...)
+	infoList = n.Left.appendSubtree(infoList)
+	return n.Right.appendSubtree(infoList)
+}
+
 // DumpTrie dumps trie for debugging
 func DumpTrie(t *Trie) {
-	root := t.Root
-	if root != nil {
-		DumpNode(root, -1)
+	for _, root := range []*Node{t.Root4, t.Root6} {
+		if root != nil {
+			DumpNode(root, -1)
+		}
 	}
 }
 
@@ -160,7 +359,7 @@ func DumpNode(n *Node, dir int) {
 		fmt.Println(relation)
 
 		for _, info := range n.Info {
-			fmt.Println("\t", info.Subnet, info.Cidr, info.Asn)
+			fmt.Println("\t", info.Subnet, info.Cidr, info.Origin())
 		}
 
 		DumpNode(n.Left, 0)
@@ -2,6 +2,7 @@ package asnlookup
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -45,9 +46,29 @@ func TestInsertFindIPv4(t *testing.T) {
 
 			ipToFind: "192.168.1.5/32",
 			want: NodeInfoList{
-				{"192.168.1.0", 24, 351},
-				{"192.168.0.0", 20, 600},
-				{"192.168.0.0", 16, 355},
+				{Subnet: "192.168.1.0", Cidr: 24, Asn: 351},
+				{Subnet: "192.168.0.0", Cidr: 20, Asn: 600},
+				{Subnet: "192.168.0.0", Cidr: 16, Asn: 355},
+			},
+			err: nil,
+		},
+		{
+			name: "Insert-Find IPv4 Default Route",
+			ipCidrList: []ipCidrAsn{
+				{
+					"0.0.0.0/0",
+					100,
+				},
+				{
+					"192.168.0.0/16",
+					355,
+				},
+			},
+
+			ipToFind: "192.168.1.5/32",
+			want: NodeInfoList{
+				{Subnet: "192.168.0.0", Cidr: 16, Asn: 355},
+				{Subnet: "0.0.0.0", Cidr: 0, Asn: 100},
 			},
 			err: nil,
 		},
@@ -116,9 +137,9 @@ func TestInsertFindIPv6(t *testing.T) {
 
 			ipToFind: "2001:db8:0:b::1A:1c/128",
 			want: NodeInfoList{
-				{"2001:0db8:0000:000b:0000:0000:0000:0000", 80, 300},
-				{"2001:0db8:0000:000b:0000:0000:0000:0000", 67, 550},
-				{"2001:0db8:0000:000b:0000:0000:0000:0000", 64, 451},
+				{Subnet: "2001:db8:0:b::", Cidr: 80, Asn: 300},
+				{Subnet: "2001:db8:0:b::", Cidr: 67, Asn: 550},
+				{Subnet: "2001:db8:0:b::", Cidr: 64, Asn: 451},
 			},
 			err: nil,
 		},
@@ -150,3 +171,77 @@ func TestInsertFindIPv6(t *testing.T) {
 		}
 	}
 }
+
+func TestInsertFindDualStack(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ipToFind string
+		want     NodeInfoList
+	}{
+		{
+			name:     "Find IPv4 Address In Dual Stack Trie",
+			ipToFind: "8.8.8.8/32",
+			want: NodeInfoList{
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+		},
+		{
+			name:     "Find IPv6 Address In Dual Stack Trie",
+			ipToFind: "800::1/128",
+			want: NodeInfoList{
+				{Subnet: "800::", Cidr: 9, Asn: 452},
+			},
+		},
+	}
+
+	// IPv4 & IPv6 prefixes below have identical leading bits
+	trie := NewTrie()
+	ipv4Address, _ := newIPv4Address("8.0.0.0/9", 352)
+	Insert(trie, ipv4Address)
+	ipv6Address, _ := newIPv6Address("800::/9", 452)
+	Insert(trie, ipv6Address)
+
+	for _, testCase := range testCases {
+		var ipToFind IPAddress
+		var err error
+		if strings.Contains(testCase.ipToFind, ":") {
+			ipToFind, err = newIPv6Address(testCase.ipToFind, -1)
+		} else {
+			ipToFind, err = newIPv4Address(testCase.ipToFind, -1)
+		}
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		got := Find(&Config{IPToFind: ipToFind, trie: trie})
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestNodeInfoOrigin(t *testing.T) {
+	testCases := []struct {
+		name string
+		info NodeInfo
+		want string
+	}{
+		{
+			name: "Origin Of Single ASN",
+			info: NodeInfo{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+			want: "15169",
+		},
+		{
+			name: "Origin Of AS Set",
+			info: NodeInfo{Subnet: "8.8.8.0", Cidr: 24, Asn: 1, ASSet: []ASN{1, 2, 3}},
+			want: "{1,2,3}",
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := testCase.info.Origin()
+		if got != testCase.want {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package asnlookup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheMaxAge is how long cached table is used without
+// revalidating it with the server
+const DefaultCacheMaxAge = time.Hour
+
+// WithCacheDir caches tables fetched by FetchTable in directory "dir".
+// Cached table is revalidated with If-None-Match and If-Modified-Since
+// once it is older than max age (see WithCacheMaxAge). If fetch fails,
+// cached table is used and a warning is reported (see WithWarningFunc).
+func WithCacheDir(dir string) Option {
+	return func(o *tableOptions) {
+		o.cacheDir = dir
+	}
+}
+
+// WithCacheMaxAge sets how long cached table is used without
+// revalidating it. Zero max age revalidates on every fetch. By default
+// DefaultCacheMaxAge is used.
+func WithCacheMaxAge(maxAge time.Duration) Option {
+	return func(o *tableOptions) {
+		o.cacheMaxAge = maxAge
+	}
+}
+
+// WithWarningFunc sets function called with problems which do not stop
+// table from loading, such as cached table being used because fetch failed
+func WithWarningFunc(warn func(error)) Option {
+	return func(o *tableOptions) {
+		o.warn = warn
+	}
+}
+
+// DefaultCacheDir returns "asnlookup" directory under user's cache
+// directory, $XDG_CACHE_HOME or ~/.cache on Linux
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "asnlookup"), nil
+}
+
+// cacheMeta is stored next to cached table and holds validators sent by
+// server with the table
+type cacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// tableCache is cached copy of table fetched from "url"
+type tableCache struct {
+	dir string
+	url string
+}
+
+// path returns path of cached file with "ext" extension. Files are named
+// by hash of URL, so tables from different URLs do not overwrite each other.
+func (c tableCache) path(ext string) string {
+	sum := sha256.Sum256([]byte(c.url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8])+ext)
+}
+
+// readMeta returns metadata of cached table. It returns false if table is
+// not cached.
+func (c tableCache) readMeta() (cacheMeta, bool) {
+	var meta cacheMeta
+	text, err := ioutil.ReadFile(c.path(".json"))
+	if err != nil || json.Unmarshal(text, &meta) != nil || meta.URL != c.url {
+		return meta, false
+	}
+
+	if _, err := os.Stat(c.path(".table")); err != nil {
+		return meta, false
+	}
+
+	return meta, true
+}
+
+// writeMeta stores metadata of cached table
+func (c tableCache) writeMeta(meta cacheMeta) error {
+	text, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return c.writeFile(".json", func(f io.Writer) error {
+		_, err := f.Write(text)
+		return err
+	})
+}
+
+// store saves table read from "body" and its metadata in cache
+func (c tableCache) store(body io.Reader, meta cacheMeta) error {
+	err := c.writeFile(".table", func(f io.Writer) error {
+		_, err := io.Copy(f, body)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.writeMeta(meta)
+}
+
+// writeFile writes cached file with "ext" extension using "write". File
+// is written to temporary file first and renamed, so readers never see
+// partially written file.
+func (c tableCache) writeFile(ext string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path(ext))
+}
+
+// open opens cached table
+func (c tableCache) open() (io.ReadCloser, error) {
+	return os.Open(c.path(".table"))
+}
+
+// fetchCached returns table fetched from "url" through cache in
+// o.cacheDir. Cached table is used as is while it is younger than
+// o.cacheMaxAge, and revalidated with the server after that. If fetch
+// fails, cached table is used with a warning.
+func fetchCached(ctx context.Context, url string, o *tableOptions) (io.ReadCloser, error) {
+	c := tableCache{dir: o.cacheDir, url: url}
+	meta, cached := c.readMeta()
+	if cached && time.Since(meta.FetchedAt) < o.cacheMaxAge {
+		return c.open()
+	}
+
+	header := http.Header{}
+	if cached {
+		if meta.ETag != "" {
+			header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	err := revalidate(ctx, c, header, meta, o)
+	if err == nil {
+		return c.open()
+	} else if !cached || ctx.Err() != nil {
+		return nil, err
+	}
+
+	if o.warn != nil {
+		o.warn(fmt.Errorf("Using cached table fetched at %s: %v", meta.FetchedAt.Format(time.RFC3339), err))
+	}
+	return c.open()
+}
+
+// revalidate fetches table with conditional "header" and updates cache
+// with the response
+func revalidate(ctx context.Context, c tableCache, header http.Header, meta cacheMeta, o *tableOptions) error {
+	resp, err := fetch(ctx, c.url, header, o)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		meta.FetchedAt = time.Now()
+		return c.writeMeta(meta)
+	}
+
+	return c.store(resp.Body, cacheMeta{
+		URL:          c.url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	})
+}
@@ -0,0 +1,118 @@
+package asnlookup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFetchTableCache(t *testing.T) {
+	// Server serves "body" with "etag" and fails when "fail" is set
+	body, etag, fail := "8.8.8.0/24 350\n", `"v1"`, false
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	warnings := 0
+	fetchTable := func(maxAge time.Duration) (*Table, error) {
+		return FetchTable(server.URL+"/table.txt",
+			WithHTTPClient(server.Client()),
+			WithRetries(0, 0),
+			WithCacheDir(dir),
+			WithCacheMaxAge(maxAge),
+			WithWarningFunc(func(error) { warnings++ }))
+	}
+
+	testCases := []struct {
+		name            string
+		setUpFunc       func()
+		maxAge          time.Duration
+		want            NodeInfoList
+		wantRequests    int
+		wantNotModified int
+		wantWarnings    int
+	}{
+		{
+			name:         "Fetch Table Into Empty Cache",
+			setUpFunc:    func() {},
+			maxAge:       time.Hour,
+			want:         NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 350}},
+			wantRequests: 1,
+		},
+		{
+			name:         "Use Cached Table Within Max Age",
+			setUpFunc:    func() { body = "8.8.8.0/24 351\n" },
+			maxAge:       time.Hour,
+			want:         NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 350}},
+			wantRequests: 1,
+		},
+		{
+			name:            "Revalidate Unchanged Table",
+			setUpFunc:       func() {},
+			maxAge:          0,
+			want:            NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 350}},
+			wantRequests:    2,
+			wantNotModified: 1,
+		},
+		{
+			name:            "Revalidate Changed Table",
+			setUpFunc:       func() { etag = `"v2"` },
+			maxAge:          0,
+			want:            NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 351}},
+			wantRequests:    3,
+			wantNotModified: 1,
+		},
+		{
+			name:            "Fall Back To Cached Table When Fetch Fails",
+			setUpFunc:       func() { fail = true },
+			maxAge:          0,
+			want:            NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 351}},
+			wantRequests:    4,
+			wantNotModified: 1,
+			wantWarnings:    1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.setUpFunc()
+		table, err := fetchTable(testCase.maxAge)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		got, _ := table.Lookup("8.8.8.8")
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+
+		if requests != testCase.wantRequests || notModified != testCase.wantNotModified || warnings != testCase.wantWarnings {
+			t.Fatalf("%s: requests do not match: got %v/%v/%v, want %v/%v/%v", testCase.name,
+				requests, notModified, warnings, testCase.wantRequests, testCase.wantNotModified, testCase.wantWarnings)
+		}
+	}
+
+	// Without cached copy, failed fetch is an error
+	dir = t.TempDir()
+	if _, err := fetchTable(0); err == nil {
+		t.Fatalf("received error does not match: got %v, want non-nil error", err)
+	}
+}
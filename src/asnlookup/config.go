@@ -1,20 +1,16 @@
 package asnlookup
 
 import (
-	"bufio"
 	"errors"
-	"io"
-	"io/ioutil"
-	"net/http"
+	"fmt"
 	"os"
-	"strconv"
 	"strings"
 )
 
 type Config struct {
 	IPToFind      IPAddress
 	IPAddressList []IPAddress
-	trie          *Trie
+	trie          RouteTrie
 }
 
 var (
@@ -29,14 +25,14 @@ var (
 )
 
 // GetConfig generates configuration and creates trie for lookup.
+// It is a command line oriented wrapper around LoadTable & FetchTable.
 // It uses CONFIG_FILE_PATH environment variable (to get IP, CIDR & ASN information) if defined.
-// Otherwise it uses default URL address to fetch configuration from.
+// Otherwise it uses default URL address to fetch configuration from, and keeps
+// a copy of it in DefaultCacheDir.
 // It also gets target IP to lookup from command line arguments.
 // It returns a pointer to Config structure which holds all this information.
 func GetConfig(envTargetIP ...string) (*Config, error) {
 
-	cfg := &Config{}
-
 	// If target IP is passed in as an argument, then use that.
 	// Otherwise get it from environment variable.
 	var reqIPStr string
@@ -53,86 +49,71 @@ func GetConfig(envTargetIP ...string) (*Config, error) {
 		reqIPStr = args[0]
 	}
 
-	var newIPAddressFunc func(string, int) (IPAddress, error)
-	var isValidCidrFunc func(string) bool
-
-	// Setup correct information for trie creation based on IP address type
-	if isValidIPv4(reqIPStr) {
-		ipToFind, err := newIPv4Address(reqIPStr+"/32", -1)
-		if err != nil {
-			return nil, err
-		}
+	// Validate target IP before fetching configuration
+	ipToFind, err := newIPToFind(reqIPStr)
+	if err != nil {
+		return nil, err
+	}
 
-		cfg.IPToFind = ipToFind
-		newIPAddressFunc = newIPv4Address
-		isValidCidrFunc = isValidIPv4Cidr
-	} else if isValidIPv6(reqIPStr) {
-		ipToFind, err := newIPv6Address(reqIPStr+"/128", -1)
-		if err != nil {
-			return nil, err
-		}
-		cfg.IPToFind = ipToFind
-		newIPAddressFunc = newIPv6Address
-		isValidCidrFunc = isValidIPv6Cidr
-	} else {
-		return nil, ErrInvalidInputIPAddress
+	cfg := &Config{
+		IPToFind: ipToFind,
 	}
 
-	cfg.trie = NewTrie()
+	// Collect every address inserted into trie in IPAddressList
+	collect := withInsertHook(func(ip IPAddress) {
+		cfg.IPAddressList = append(cfg.IPAddressList, ip)
+	})
 
 	// Get configuration either from CONFIG_FILE_PATH environment variable or
-	// default configURL
-	var reader io.Reader
-	configURL := "http://lg01.infra.ring.nlnog.net/table.txt"
+	// default URL
+	var table *Table
 	configFile := os.Getenv("CONFIG_FILE_PATH")
-
 	if configFile == "" {
-		resp, err := http.Get(configURL)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-		reader = resp.Body
+		table, err = FetchTable(DefaultTableURL, collect, withDefaultCache())
 	} else {
-
-		file, err := os.Open(configFile)
-		if err != nil {
-			return nil, err
-		}
-		defer file.Close()
-
-		reader = file
+		table, err = LoadTable(configFile, collect)
 	}
-
-	text, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
-	// Scan the text line by line and insert ipAddress information into trie
-	scanner := bufio.NewScanner(strings.NewReader(string(text)))
-	for scanner.Scan() {
-		parts := strings.Split(strings.Trim(scanner.Text(), " "), " ")
-		if len(parts) != 2 {
-			continue
+	cfg.trie = table.load().trie
+	return cfg, nil
+}
+
+// newIPToFind converts target IP address string into IPAddress with
+// host prefix length. Target can also be a prefix in CIDR format, e.g.
+// 203.0.113.0/24, which keeps its prefix length.
+func newIPToFind(reqIPStr string) (IPAddress, error) {
+	if strings.Contains(reqIPStr, "/") {
+		ipToFind, err := newRouteAddress(reqIPStr, -1)
+		if err != nil {
+			return nil, ErrInvalidInputIPAddress
 		}
+		return ipToFind, nil
+	}
 
-		isValidCidr := isValidCidrFunc(parts[0])
-		if isValidCidr == true {
-			asn, err := strconv.Atoi(parts[1])
-			if err != nil {
-				continue
-			}
+	if isValidIPv4(reqIPStr) {
+		return newIPv4Address(reqIPStr+"/32", -1)
+	} else if isValidIPv6(reqIPStr) {
+		return newIPv6Address(reqIPStr+"/128", -1)
+	}
+
+	return nil, ErrInvalidInputIPAddress
+}
 
-			ipAddress, err := newIPAddressFunc(parts[0], asn)
-			if err != nil {
-				continue
-			}
+// withDefaultCache caches fetched table in DefaultCacheDir and prints
+// warnings on stderr. Table is not cached if there is no cache directory.
+func withDefaultCache() Option {
+	return func(o *tableOptions) {
+		dir, err := DefaultCacheDir()
+		if err != nil {
+			return
+		}
 
-			Insert(cfg.trie, ipAddress)
-			cfg.IPAddressList = append(cfg.IPAddressList, ipAddress)
+		o.cacheDir = dir
+		o.warn = func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
 		}
 	}
-
-	return cfg, nil
 }
@@ -38,6 +38,21 @@ func TestGetConfig(t *testing.T) {
 			asnList: []int{350, 352, 351, 156},
 			err:     nil,
 		},
+		{
+			name:        "Read IPv4 & IPv6 Configuration For IPv6 Target",
+			setUpFunc:   func() {},
+			ipToFindStr: "2604:a880:2:d0::1",
+			ipAddressListStr: []string{
+				"8.8.8.0",
+				"8.0.0.0",
+				"8.0.0.0",
+				"192.121.43.0",
+				"2604:a880:2:d0::",
+				"2604:a880:2:d0::",
+			},
+			asnList: []int{350, 352, 351, 156, 440, 444},
+			err:     nil,
+		},
 	}
 
 	_, cfgFileDefined := os.LookupEnv("CONFIG_FILE_PATH")
@@ -0,0 +1,278 @@
+package asnlookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of table download options
+const (
+	// DefaultConnectTimeout is how long connecting to table server may take
+	DefaultConnectTimeout = 10 * time.Second
+
+	// DefaultReadTimeout is how long table server may stall without
+	// sending any data
+	DefaultReadTimeout = 30 * time.Second
+
+	// DefaultRetries is how many times failed download is retried
+	DefaultRetries = 2
+
+	// DefaultRetryBackoff is wait before first retry. It doubles on every
+	// following retry.
+	DefaultRetryBackoff = time.Second
+
+	// DefaultMaxTableSize is maximum size of downloaded table in bytes
+	DefaultMaxTableSize = 1 << 30
+
+	// maxRetryBackoff is longest wait between retries
+	maxRetryBackoff = 5 * time.Minute
+)
+
+var (
+	// ErrReadTimeout is returned when table server stalls for longer than
+	// read timeout
+	ErrReadTimeout = errors.New("Timed out waiting for table server")
+
+	// ErrTableTooLarge is returned when downloaded table is larger than
+	// maximum table size
+	ErrTableTooLarge = errors.New("Table is larger than maximum table size")
+)
+
+// FetchError is returned when downloading table fails. It tells which URL
+// and which attempt failed.
+type FetchError struct {
+	URL      string
+	Attempt  int
+	Attempts int
+	Err      error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("Fetching %s failed on attempt %d of %d: %v", e.URL, e.Attempt, e.Attempts, e.Err)
+}
+
+// Unwrap returns underlying error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// WithTimeouts sets how long connecting to table server may take and how
+// long server may stall without sending any data. Zero disables timeout.
+// Connect timeout is not used with client set by WithHTTPClient. By
+// default DefaultConnectTimeout and DefaultReadTimeout are used.
+func WithTimeouts(connect, read time.Duration) Option {
+	return func(o *tableOptions) {
+		o.connectTimeout = connect
+		o.readTimeout = read
+	}
+}
+
+// WithRetries sets how many times download is retried after network
+// errors and 5xx responses. First retry waits for "backoff" and every
+// following retry waits twice as long as previous one, up to 5 minutes.
+// Negative "retries" means no retries. Errors while reading table body
+// are not retried. By default DefaultRetries and DefaultRetryBackoff are
+// used.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(o *tableOptions) {
+		o.retries = retries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithMaxTableSize sets maximum size of downloaded table in bytes. Size
+// of zero or less means no limit. By default DefaultMaxTableSize is used.
+func WithMaxTableSize(size int64) Option {
+	return func(o *tableOptions) {
+		o.maxTableSize = size
+	}
+}
+
+// client returns HTTP client used for downloads
+func (o *tableOptions) client() *http.Client {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+
+	dialer := &net.Dialer{Timeout: o.connectTimeout}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: o.connectTimeout,
+			DisableKeepAlives:   true,
+		},
+	}
+}
+
+// fetch sends GET request with "header" for "url" and returns response
+// with status 200 or 304. Network errors and 5xx responses are retried.
+// Errors, also those returned while reading response body, are *FetchError.
+func fetch(ctx context.Context, url string, header http.Header, o *tableOptions) (*http.Response, error) {
+	client := o.client()
+	attempts := 1
+	if o.retries > 0 {
+		attempts += o.retries
+	}
+	backoff := o.retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		resp, retry, err := fetchOnce(ctx, client, url, header, o)
+		if err == nil {
+			resp.Body.(*fetchBody).attempt = attempt
+			resp.Body.(*fetchBody).attempts = attempts
+			return resp, nil
+		}
+
+		err = &FetchError{URL: url, Attempt: attempt, Attempts: attempts, Err: err}
+		if !retry || attempt >= attempts || ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+
+		if backoff < maxRetryBackoff {
+			backoff *= 2
+			if backoff > maxRetryBackoff {
+				backoff = maxRetryBackoff
+			}
+		}
+	}
+}
+
+// fetchOnce makes one download attempt. It returns whether failed attempt
+// may be retried.
+func fetchOnce(ctx context.Context, client *http.Client, url string, header http.Header, o *tableOptions) (*http.Response, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	d := newDeadline(o.readTimeout, cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		d.stop()
+		cancel()
+		return nil, false, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		d.stop()
+		cancel()
+		return nil, true, d.err(err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotModified {
+		resp.Body.Close()
+		d.stop()
+		cancel()
+		return nil, resp.StatusCode >= 500, errors.New(resp.Status)
+	}
+
+	resp.Body = &fetchBody{
+		body:      resp.Body,
+		cancel:    cancel,
+		deadline:  d,
+		url:       url,
+		limited:   o.maxTableSize > 0,
+		remaining: o.maxTableSize,
+	}
+	return resp, false, nil
+}
+
+// deadline cancels request when no data is received for "timeout"
+type deadline struct {
+	timer   *time.Timer
+	timeout time.Duration
+	expired int32
+}
+
+// newDeadline returns deadline calling "cancel" after "timeout". Zero
+// timeout never expires.
+func newDeadline(timeout time.Duration, cancel context.CancelFunc) *deadline {
+	d := &deadline{timeout: timeout}
+	if timeout > 0 {
+		d.timer = time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&d.expired, 1)
+			cancel()
+		})
+	}
+
+	return d
+}
+
+// reset restarts deadline after data was received
+func (d *deadline) reset() {
+	if d.timer != nil {
+		d.timer.Reset(d.timeout)
+	}
+}
+
+func (d *deadline) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// err returns ErrReadTimeout instead of "err" if deadline expired
+func (d *deadline) err(err error) error {
+	if atomic.LoadInt32(&d.expired) == 1 {
+		return ErrReadTimeout
+	}
+
+	return err
+}
+
+// fetchBody is body of downloaded table. It enforces read timeout and
+// maximum table size and wraps read errors in *FetchError.
+type fetchBody struct {
+	body      io.ReadCloser
+	cancel    context.CancelFunc
+	deadline  *deadline
+	url       string
+	attempt   int
+	attempts  int
+	limited   bool
+	remaining int64
+}
+
+func (b *fetchBody) Read(p []byte) (int, error) {
+	// Read one byte more than allowed to detect too large table
+	if b.limited && int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.body.Read(p)
+	b.deadline.reset()
+	if b.limited {
+		if int64(n) > b.remaining {
+			// Do not return the byte read beyond the limit
+			n = int(b.remaining)
+			b.remaining = 0
+			return n, &FetchError{URL: b.url, Attempt: b.attempt, Attempts: b.attempts, Err: ErrTableTooLarge}
+		}
+		b.remaining -= int64(n)
+	}
+
+	if err != nil && err != io.EOF {
+		err = &FetchError{URL: b.url, Attempt: b.attempt, Attempts: b.attempts, Err: b.deadline.err(err)}
+	}
+	return n, err
+}
+
+func (b *fetchBody) Close() error {
+	b.deadline.stop()
+	b.cancel()
+	return b.body.Close()
+}
@@ -0,0 +1,219 @@
+package asnlookup
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchTableErrors(t *testing.T) {
+	// Server fails with "failStatus" for first "failures" requests. When
+	// "stallAfter" is not negative, it stalls after sending that many
+	// bytes of table until request is canceled.
+	var failures, failStatus, stallAfter, requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			http.Error(w, "failure", failStatus)
+			return
+		}
+
+		if stallAfter < 0 {
+			w.Write([]byte(tableTestText))
+			return
+		}
+
+		w.Write([]byte(tableTestText[:stallAfter]))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name         string
+		failures     int
+		failStatus   int
+		stallAfter   int
+		opts         []Option
+		wantRequests int
+		wantErr      error
+		wantAttempt  int
+		wantAttempts int
+	}{
+		{
+			name:         "Retry Server Errors",
+			failures:     2,
+			failStatus:   http.StatusServiceUnavailable,
+			stallAfter:   -1,
+			wantRequests: 3,
+			wantErr:      nil,
+		},
+		{
+			name:         "Give Up After Retries",
+			failures:     5,
+			failStatus:   http.StatusInternalServerError,
+			stallAfter:   -1,
+			wantRequests: 3,
+			wantAttempt:  3,
+		},
+		{
+			name:         "Do Not Retry With Negative Retries",
+			failures:     5,
+			failStatus:   http.StatusInternalServerError,
+			stallAfter:   -1,
+			opts:         []Option{WithRetries(-1, time.Millisecond)},
+			wantRequests: 1,
+			wantAttempt:  1,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Do Not Retry Client Errors",
+			failures:     1,
+			failStatus:   http.StatusNotFound,
+			stallAfter:   -1,
+			wantRequests: 1,
+			wantAttempt:  1,
+		},
+		{
+			name:         "Time Out Stalled Response",
+			stallAfter:   0,
+			wantRequests: 1,
+			wantErr:      ErrReadTimeout,
+			wantAttempt:  1,
+		},
+		{
+			name:         "Time Out Stalled Body",
+			stallAfter:   20,
+			wantRequests: 1,
+			wantErr:      ErrReadTimeout,
+			wantAttempt:  1,
+		},
+		{
+			name:         "Reject Too Large Table",
+			stallAfter:   -1,
+			opts:         []Option{WithMaxTableSize(10)},
+			wantRequests: 1,
+			wantErr:      ErrTableTooLarge,
+			wantAttempt:  1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		failures, failStatus, stallAfter, requests = testCase.failures, testCase.failStatus, testCase.stallAfter, 0
+		opts := append([]Option{
+			WithHTTPClient(server.Client()),
+			WithTimeouts(0, 50*time.Millisecond),
+			WithRetries(2, time.Millisecond),
+		}, testCase.opts...)
+
+		url := server.URL + "/table.txt"
+		_, err := FetchTable(url, opts...)
+		if requests != testCase.wantRequests {
+			t.Fatalf("%s: requests do not match: got %v, want %v", testCase.name, requests, testCase.wantRequests)
+		}
+
+		if testCase.wantAttempt == 0 {
+			if err != nil {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+			}
+			continue
+		}
+
+		wantAttempts := testCase.wantAttempts
+		if wantAttempts == 0 {
+			wantAttempts = 3
+		}
+
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) || fetchErr.URL != url || fetchErr.Attempt != testCase.wantAttempt || fetchErr.Attempts != wantAttempts {
+			t.Fatalf("%s: received error does not match: got %v, want error of attempt %v of %v for %v", testCase.name, err, testCase.wantAttempt, wantAttempts, url)
+		}
+
+		if testCase.wantErr != nil && !errors.Is(err, testCase.wantErr) {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.wantErr)
+		}
+
+		if !strings.Contains(err.Error(), url) {
+			t.Fatalf("%s: error does not name URL: got %v, want %v", testCase.name, err, url)
+		}
+	}
+}
+
+func TestFetchBodyMaxTableSize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		size    int64
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "Read Table Within Limit",
+			size:    int64(len(tableTestText)),
+			want:    tableTestText,
+			wantErr: nil,
+		},
+		{
+			name:    "Stop At Limit",
+			size:    10,
+			want:    tableTestText[:10],
+			wantErr: ErrTableTooLarge,
+		},
+		{
+			name:    "Zero Size Is Unlimited",
+			size:    0,
+			want:    tableTestText,
+			wantErr: nil,
+		},
+		{
+			name:    "Negative Size Is Unlimited",
+			size:    -5,
+			want:    tableTestText,
+			wantErr: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		o := newTableOptions([]Option{WithMaxTableSize(testCase.size)})
+		body := &fetchBody{
+			body:      ioutil.NopCloser(strings.NewReader(tableTestText)),
+			cancel:    func() {},
+			deadline:  newDeadline(0, nil),
+			limited:   o.maxTableSize > 0,
+			remaining: o.maxTableSize,
+		}
+
+		got, err := ioutil.ReadAll(body)
+		if !errors.Is(err, testCase.wantErr) {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.wantErr)
+		}
+
+		if string(got) != testCase.want {
+			t.Fatalf("%s: result does not match: got %q, want %q", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestFetchTableContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := FetchTableContext(ctx, server.URL, WithHTTPClient(server.Client()), WithTimeouts(0, 0))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("received error does not match: got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("fetch was not canceled: took %v", elapsed)
+	}
+}
@@ -8,6 +8,31 @@ type IPAddress interface {
 	GetString() string
 	GetNthHighestBit(n uint8) uint8
 	GetAsn() int
+	GetASSet() []int
 	GetCidrLen() int
 	GetNumBitsInAddress() int
 }
+
+// RouteTrie interface contains methods to store and look up routes.
+// Trie (bit by bit binary trie) & RadixTrie (path compressed trie)
+// satisfy this interface and return same results from Find(),
+// FindCovered() and LongestMatch().
+//
+// Insert stores "ip" according to InsertPolicy of the trie. It returns
+// false if route was not stored, and routes with same prefix it replaced.
+// Update stores "ip" as the only route with its prefix. Delete removes
+// routes with prefix of "ip" and given origin ASN and prunes nodes left
+// empty, so incremental updates keep the trie same as a fresh build. Use
+// NewRoute to create "ip" for a prefix.
+// Walk and WalkPrefix visit routes in address order until WalkFunc
+// returns false.
+type RouteTrie interface {
+	Insert(ip IPAddress) (bool, NodeInfoList)
+	Update(ip IPAddress) NodeInfoList
+	Delete(ip IPAddress, asn ASN) NodeInfoList
+	Find(ip IPAddress) NodeInfoList
+	FindCovered(ip IPAddress) NodeInfoList
+	LongestMatch(ip IPAddress) (NodeInfo, bool)
+	Walk(fn WalkFunc) bool
+	WalkPrefix(ip IPAddress, fn WalkFunc) bool
+}
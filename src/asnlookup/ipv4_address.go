@@ -22,6 +22,7 @@ type IPv4Address struct {
 	ip      uint32
 	ipStr   string
 	asn     int
+	asSet   []int
 }
 
 // Compile time check to ensure IPv4Address satiesfies IPAddress interface
@@ -77,6 +78,12 @@ func (ipv4 IPv4Address) GetAsn() int {
 	return ipv4.asn
 }
 
+// GetASSet returns members of AS_SET origin stored in IPv4 address, nil
+// if origin is a single ASN. This method is needed to satisfy IPAddress interface
+func (ipv4 IPv4Address) GetASSet() []int {
+	return ipv4.asSet
+}
+
 // GetCidrLen returns CIDR prefix length stored in IPv4 address.
 // This method is needed to satisfy IPAddress interface
 func (ipv4 IPv4Address) GetCidrLen() int {
@@ -116,7 +123,7 @@ func isValidIPv4(ip string) bool {
 		return false
 	}
 
-	for idx, o := range octets {
+	for _, o := range octets {
 		num, err := strconv.Atoi(o)
 		if err != nil {
 			return false
@@ -125,10 +132,6 @@ func isValidIPv4(ip string) bool {
 		if num < 0 || num > 255 {
 			return false
 		}
-
-		if idx == 0 && num == 0 {
-			return false
-		}
 	}
 
 	return true
@@ -149,7 +152,7 @@ func isValidIPv4Cidr(cidr string) bool {
 		return false
 	}
 
-	if num < 1 || num > 32 {
+	if num < 0 || num > 32 {
 		return false
 	}
 
@@ -173,8 +176,7 @@ func intToIPv4Str(ip uint32) (string, error) {
 	var ipStr []string
 	for i := 1; i <= 4; i++ {
 		octet := (ip >> uint32(32-8*i)) & 0xFF
-		if (i == 1 && (octet < 1 || octet > 255)) ||
-			(octet < 0 || octet > 255) {
+		if octet < 0 || octet > 255 {
 			return "", ErrInvalidIPv4Address
 		}
 
@@ -261,10 +261,10 @@ func TestParseIPv4(t *testing.T) {
 			err:  ErrInvalidIPv4Address,
 		},
 		{
-			name: "Parse Invalid IPv4 Address With All 0",
+			name: "Parse Valid IPv4 Address With All 0",
 			ip:   "0.0.0.0",
-			want: []byte{},
-			err:  ErrInvalidIPv4Address,
+			want: []byte{0, 0, 0, 0},
+			err:  nil,
 		},
 		{
 			name: "Parse Valid IPv4 Address With 0",
@@ -307,10 +307,10 @@ func TestIPv4StrToInt(t *testing.T) {
 			err:  nil,
 		},
 		{
-			name: "InValid IPv4 Address With All 0s",
+			name: "Valid IPv4 Address With All 0s",
 			ip:   "0.0.0.0",
 			want: 0,
-			err:  ErrInvalidIPv4Address,
+			err:  nil,
 		},
 		{
 			name: "Valid IPv4 Address With 0s In Lower Three Octets",
@@ -359,10 +359,10 @@ func TestIntToIPv4Str(t *testing.T) {
 			err:  nil,
 		},
 		{
-			name: "InValid IPv4 Address With All 0s",
+			name: "Valid IPv4 Address With All 0s",
 			ip:   0,
-			want: "",
-			err:  ErrInvalidIPv4Address,
+			want: "0.0.0.0",
+			err:  nil,
 		},
 		{
 			name: "Valid IPv4 Address With 0s In Lower Three Octets",
@@ -402,14 +402,14 @@ func TestIsValidIPv4(t *testing.T) {
 			want: true,
 		},
 		{
-			name: "Invalid IPv4 Address With All 0",
+			name: "Valid IPv4 Address With All 0",
 			ip:   "0.0.0.0",
-			want: false,
+			want: true,
 		},
 		{
-			name: "Invalid IPv4 Address With 0 In Highest Octet",
+			name: "Valid IPv4 Address With 0 In Highest Octet",
 			ip:   "0.1.1.1",
-			want: false,
+			want: true,
 		},
 		{
 			name: "Not Enough Octets for IPv4 Address",
@@ -477,7 +477,7 @@ func TestIsValidIPv4Cidr(t *testing.T) {
 		{
 			name: "Zero CIDR for IPv4 Address",
 			ip:   "5.5.5.5/0",
-			want: false,
+			want: true,
 		},
 		{
 			name: "More Than One / In Mask At Different Places",
@@ -24,6 +24,7 @@ type IPv6Address struct {
 	ip      [2]uint64
 	ipStr   string
 	asn     int
+	asSet   []int
 }
 
 // Compile time check to ensure IPv6Address satiesfies IPAddress interface
@@ -106,6 +107,12 @@ func (ipv6 IPv6Address) GetAsn() int {
 	return ipv6.asn
 }
 
+// GetASSet returns members of AS_SET origin stored in IPv6 address, nil
+// if origin is a single ASN. This method is needed to satisfy IPAddress interface
+func (ipv6 IPv6Address) GetASSet() []int {
+	return ipv6.asSet
+}
+
 // GetCidrLen returns CIDR prefix length stored in IPv6 address.
 // This method is needed to satisfy IPAddress interface
 func (ipv6 IPv6Address) GetCidrLen() int {
@@ -125,6 +132,9 @@ func parseIPv6(ipStr string) ([]byte, error) {
 		return []byte{}, ErrInvalidIPv6Address
 	}
 
+	// Address is valid, so embedded IPv4 address is valid too
+	ipStr, _ = embeddedIPv4ToHex(ipStr)
+
 	bytes := make([]byte, 32)
 	ipStr = strings.ToLower(ipStr)
 	ipv6Address := make([]byte, 16)
@@ -194,6 +204,10 @@ func parseIPv6(ipStr string) ([]byte, error) {
 }
 
 func isValidIPv6(ip string) bool {
+	ip, ok := embeddedIPv4ToHex(ip)
+	if !ok {
+		return false
+	}
 
 	cHextects := strings.Split(ip, "::")
 	if len(cHextects) > 2 {
@@ -243,6 +257,24 @@ func isValidIPv6(ip string) bool {
 	return true
 }
 
+// embeddedIPv4ToHex replaces IPv4 address in dotted decimal format at the
+// end of IPv6 address (::ffff:192.0.2.1, 64:ff9b::198.51.100.7) with two
+// hextets (::ffff:c000:201). It returns false if embedded IPv4 address is
+// invalid. Addresses without embedded IPv4 address are returned unchanged.
+func embeddedIPv4ToHex(ip string) (string, bool) {
+	i := strings.LastIndex(ip, ":")
+	if i < 0 || !strings.Contains(ip[i+1:], ".") {
+		return ip, true
+	}
+
+	ipv4Int, err := ipv4StrToInt(ip[i+1:])
+	if err != nil {
+		return ip, false
+	}
+
+	return fmt.Sprintf("%s%x:%x", ip[:i+1], ipv4Int>>16, ipv4Int&0xFFFF), true
+}
+
 func ipv6StrToInt(s string) ([2]uint64, error) {
 
 	var ipv6Int [2]uint64
@@ -265,10 +297,62 @@ func ipv6StrToInt(s string) ([2]uint64, error) {
 	return ipv6Int, nil
 }
 
-// This function will return IPv6 address string in uncompressed format.
-// It can be enhanced to return compressed format string in future
+// intToIPv6Str returns IPv6 address string in RFC 5952 canonical format:
+// lowercase hex without leading zeros, longest run of two or more zero
+// groups (first one on tie) compressed to "::", and IPv4-mapped addresses
+// with last 32 bits in dotted decimal (::ffff:192.0.2.1)
 func intToIPv6Str(ip [2]uint64) (string, error) {
+	var groups [8]uint64
+	for i := range groups {
+		groups[i] = (ip[i/4] >> uint64(64-16*(i%4+1))) & 0xFFFF
+	}
+
+	if ip[0] == 0 && ip[1]>>32 == 0xFFFF {
+		ipv4Str, err := intToIPv4Str(uint32(ip[1]))
+		if err != nil {
+			return "", err
+		}
+		return "::ffff:" + ipv4Str, nil
+	}
+
+	// Find longest run of zero groups
+	zeroStart, zeroLen := -1, 0
+	for i := 0; i < len(groups); {
+		j := i
+		for j < len(groups) && groups[j] == 0 {
+			j++
+		}
+		if j-i > zeroLen && j-i > 1 {
+			zeroStart, zeroLen = i, j-i
+		}
+		if j == i {
+			j++
+		}
+		i = j
+	}
+
+	var ipStr []string
+	for i := 0; i < len(groups); i++ {
+		if i == zeroStart {
+			// Empty strings around the run join into "::"
+			if i == 0 {
+				ipStr = append(ipStr, "")
+			}
+			ipStr = append(ipStr, "")
+			i += zeroLen - 1
+			if i == len(groups)-1 {
+				ipStr = append(ipStr, "")
+			}
+			continue
+		}
+		ipStr = append(ipStr, strconv.FormatUint(groups[i], 16))
+	}
+	return strings.Join(ipStr, ":"), nil
+}
 
+// intToIPv6ExpandedStr returns IPv6 address string in uncompressed format,
+// with all eight groups of four hex digits
+func intToIPv6ExpandedStr(ip [2]uint64) string {
 	var ipStr []string
 	for _, part := range ip {
 		for i := 1; i <= 4; i++ {
@@ -277,7 +361,19 @@ func intToIPv6Str(ip [2]uint64) (string, error) {
 			ipStr = append(ipStr, s)
 		}
 	}
-	return strings.Join(ipStr, ":"), nil
+	return strings.Join(ipStr, ":")
+}
+
+// ExpandIPv6 returns IPv6 address "addr" in uncompressed format, e.g.
+// 2001:0db8:0000:0000:0000:0000:0000:0001 for 2001:db8::1. Strings which
+// are not IPv6 addresses, such as IPv4 addresses, are returned unchanged.
+func ExpandIPv6(addr string) string {
+	ip, err := ipv6StrToInt(addr)
+	if err != nil {
+		return addr
+	}
+
+	return intToIPv6ExpandedStr(ip)
 }
 
 func isValidIPv6Cidr(cidr string) bool {
@@ -295,7 +391,7 @@ func isValidIPv6Cidr(cidr string) bool {
 		return false
 	}
 
-	if num < 1 || num > 128 {
+	if num < 0 || num > 128 {
 		return false
 	}
 
@@ -17,14 +17,14 @@ func TestIPv6AddressGetString(t *testing.T) {
 			name:    "Parse Valid IPv6 CIDR",
 			ipCidr:  "2604:a880:2:d0::2249:2001/64",
 			asn:     350,
-			wantStr: "2604:a880:0002:00d0:0000:0000:0000:0000",
+			wantStr: "2604:a880:2:d0::",
 			err:     nil,
 		},
 		{
 			name:    "Parse Incorrect CIDR Address That Can Be Corrected",
 			ipCidr:  "fe80::c7e:afff:fe10:66e0:12/64",
 			asn:     350,
-			wantStr: "fe80:0000:0000:0c7e:0000:0000:0000:0000",
+			wantStr: "fe80:0:0:c7e::",
 			err:     nil,
 		},
 		{
@@ -320,6 +320,26 @@ func TestIsValidIPv6(t *testing.T) {
 			ip:   "2001:db8::0:b::1a",
 			want: false,
 		},
+		{
+			name: "IPv4-mapped IPv6 Address In Dotted Form",
+			ip:   "::ffff:192.0.2.1",
+			want: true,
+		},
+		{
+			name: "Uncompressed IPv6 Address With Embedded IPv4 Address",
+			ip:   "0:0:0:0:0:ffff:192.0.2.1",
+			want: true,
+		},
+		{
+			name: "Embedded IPv4 Address With Invalid Octet",
+			ip:   "::ffff:192.0.2.256",
+			want: false,
+		},
+		{
+			name: "Embedded IPv4 Address Not At The End",
+			ip:   "::192.0.2.1:1",
+			want: false,
+		},
 
 		{
 			name: "Compressed IPv6 Address Uppercase",
@@ -387,6 +407,24 @@ func TestIPv6StrToInt(t *testing.T) {
 			want: [2]uint64{2306139568115548171, 2954937499674},
 			err:  nil,
 		},
+		{
+			name: "IPv4-mapped IPv6 Address",
+			ip:   "::ffff:192.0.2.1",
+			want: [2]uint64{0, 0x0000ffffc0000201},
+			err:  nil,
+		},
+		{
+			name: "IPv4-compatible IPv6 Address",
+			ip:   "::192.0.2.1",
+			want: [2]uint64{0, 0xc0000201},
+			err:  nil,
+		},
+		{
+			name: "NAT64 IPv6 Address",
+			ip:   "64:ff9b::198.51.100.7",
+			want: [2]uint64{0x0064ff9b00000000, 0xc6336407},
+			err:  nil,
+		},
 		{
 			name: "Uncompressed IPv6 Address Lowercase With 0 In Higher 64 Bits",
 			ip:   "0000:0000:0000:0000:0000:02b0:0000:001a",
@@ -431,58 +469,166 @@ func TestIntToIPv6Str(t *testing.T) {
 		ip   [2]uint64
 		want string
 		err  error
+	}{
+		{
+			name: "Longest Zero Run Compressed",
+			ip:   [2]uint64{2306139568115548171, 26},
+			want: "2001:db8:0:b::1a",
+			err:  nil,
+		},
+
+		{
+			name: "Single Zero Groups Not Compressed",
+			ip:   [2]uint64{2306139568115548171, 2954937499674},
+			want: "2001:db8:0:b:0:2b0:0:1a",
+			err:  nil,
+		},
+		{
+			name: "Leading Zero Run Compressed",
+			ip:   [2]uint64{0, 2954937499674},
+			want: "::2b0:0:1a",
+			err:  nil,
+		},
+		{
+			name: "Trailing Zero Run Compressed",
+			ip:   [2]uint64{2306139568115548171, 0},
+			want: "2001:db8:0:b::",
+			err:  nil,
+		},
+		{
+			name: "All Ones In Higher 64 Bits",
+			ip:   [2]uint64{18446744073709551615, 2954937499674},
+			want: "ffff:ffff:ffff:ffff:0:2b0:0:1a",
+			err:  nil,
+		},
+		{
+			name: "All Ones In Lower 64 Bits",
+			ip:   [2]uint64{2306139568115548171, 18446744073709551615},
+			want: "2001:db8:0:b:ffff:ffff:ffff:ffff",
+			err:  nil,
+		},
+		{
+			name: "Unspecified Address",
+			ip:   [2]uint64{0, 0},
+			want: "::",
+			err:  nil,
+		},
+		{
+			name: "Loopback Address",
+			ip:   [2]uint64{0, 1},
+			want: "::1",
+			err:  nil,
+		},
+		{
+			name: "First Of Equal Zero Runs Compressed",
+			ip:   [2]uint64{0x2001_0db8_0000_0000, 0x0001_0000_0000_0001},
+			want: "2001:db8::1:0:0:1",
+			err:  nil,
+		},
+		{
+			name: "Longer Later Zero Run Compressed",
+			ip:   [2]uint64{0x2001_0000_0001_0000, 0x0000_0000_0000_0001},
+			want: "2001:0:1::1",
+			err:  nil,
+		},
+		{
+			name: "IPv4-mapped Address In Dotted Form",
+			ip:   [2]uint64{0, 0x0000_ffff_c000_0201},
+			want: "::ffff:192.0.2.1",
+			err:  nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		got, err := intToIPv6Str(testCase.ip)
+		if err != testCase.err {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+		}
+
+		if got != testCase.want {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+
+}
+func TestIntToIPv6ExpandedStr(t *testing.T) {
+	testCases := []struct {
+		name string
+		ip   [2]uint64
+		want string
 	}{
 		{
 			name: "Compressed IPv6 Address Lowercase",
 			ip:   [2]uint64{2306139568115548171, 26},
 			want: "2001:0db8:0000:000b:0000:0000:0000:001a",
-			err:  nil,
 		},
 
 		{
 			name: "Uncompressed IPv6 Address Lowercase",
 			ip:   [2]uint64{2306139568115548171, 2954937499674},
 			want: "2001:0db8:0000:000b:0000:02b0:0000:001a",
-			err:  nil,
 		},
 		{
 			name: "Uncompressed IPv6 Address Lowercase With 0 In Higher 64 Bits",
 			ip:   [2]uint64{0, 2954937499674},
 			want: "0000:0000:0000:0000:0000:02b0:0000:001a",
-			err:  nil,
 		},
 		{
 			name: "Uncompressed IPv6 Address Lowercase With 0 In Lower 64 Bits",
 			ip:   [2]uint64{2306139568115548171, 0},
 			want: "2001:0db8:0000:000b:0000:0000:0000:0000",
-			err:  nil,
 		},
 		{
 			name: "Uncompressed IPv6 Address Lowercase With 1 In Higher 64 Bits",
 			ip:   [2]uint64{18446744073709551615, 2954937499674},
 			want: "ffff:ffff:ffff:ffff:0000:02b0:0000:001a",
-			err:  nil,
 		},
 		{
 			name: "Uncompressed IPv6 Address Lowercase With 1 In Lower 64 Bits",
 			ip:   [2]uint64{2306139568115548171, 18446744073709551615},
 			want: "2001:0db8:0000:000b:ffff:ffff:ffff:ffff",
-			err:  nil,
 		},
 	}
 
 	for _, testCase := range testCases {
-		got, err := intToIPv6Str(testCase.ip)
-		if err != testCase.err {
-			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+		got := intToIPv6ExpandedStr(testCase.ip)
+		if got != testCase.want {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
 		}
+	}
 
+}
+func TestExpandIPv6(t *testing.T) {
+	testCases := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{
+			name: "Expand Compressed IPv6 Address",
+			addr: "2001:db8::1",
+			want: "2001:0db8:0000:0000:0000:0000:0000:0001",
+		},
+		{
+			name: "Expand IPv4-mapped Address",
+			addr: "::ffff:192.0.2.1",
+			want: "0000:0000:0000:0000:0000:ffff:c000:0201",
+		},
+		{
+			name: "Keep IPv4 Address",
+			addr: "192.0.2.1",
+			want: "192.0.2.1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := ExpandIPv6(testCase.addr)
 		if got != testCase.want {
 			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
 		}
 	}
-
 }
+
 func TestIsValidIPv6Cidr(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -499,6 +645,11 @@ func TestIsValidIPv6Cidr(t *testing.T) {
 			ip:   "2001:0db8:0000:000b:0000:0000:0000:001a/128",
 			want: true,
 		},
+		{
+			name: "Zero CIDR for IPv6 Address",
+			ip:   "::/0",
+			want: true,
+		},
 		{
 			name: "IPv6 Mask More Than 128",
 			ip:   "2001:db8::0:b:1a/129",
@@ -0,0 +1,323 @@
+package asnlookup
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MRT record types and subtypes used by MRTReader (RFC 6396)
+const (
+	mrtTypeTableDumpV2     = 13
+	mrtSubtypeRIBIPv4      = 2
+	mrtSubtypeRIBIPv6      = 4
+	mrtHeaderLen           = 12
+	bgpAttrTypeASPath      = 2
+	bgpAttrFlagExtendedLen = 0x10
+	asPathSegmentASSet     = 1
+	asPathSegmentASSeq     = 2
+
+	// mrtMaxRecordLen is maximum accepted length of MRT record body. RIB
+	// records of full tables with hundreds of peers are far smaller.
+	mrtMaxRecordLen = 16 << 20
+)
+
+var (
+	// ErrInvalidMRT is returned when MRT record is truncated or badly
+	// formatted, or is longer than 16 MiB
+	ErrInvalidMRT = errors.New("Invalid MRT record")
+
+	// ErrMRTNoOrigin is reason of skipping MRT prefix without origin AS,
+	// e.g. locally originated route with empty AS_PATH
+	ErrMRTNoOrigin = errors.New("MRT prefix has no origin AS")
+)
+
+// MRTReader reads routes from MRT TABLE_DUMP_V2 routing information base
+// dumps (RFC 6396), such as RIPE RIS "bview" or RouteViews "rib" files.
+// Input can be gzip or bzip2 compressed. Only RIB_IPV4_UNICAST and
+// RIB_IPV6_UNICAST records are used, other records are skipped.
+//
+// Origin AS of a route is the last AS of AS_PATH attribute. When AS_PATH
+// ends with AS_SET, origin is not a single AS. Such routes are returned
+// with all members of the set (see NodeInfo.ASSet), also when the set has
+// only one member, and counted separately in ASSetRoutes.
+type MRTReader struct {
+	r       *bufio.Reader
+	pending []IPAddress
+	records int
+
+	// skip is called for every skipped prefix with number of its record,
+	// and reading stops with error it returns
+	skip func(record int, prefix string, err error) error
+
+	// Routes is number of routes returned so far
+	Routes int
+
+	// ASSetRoutes is number of returned routes with AS_SET origin
+	ASSetRoutes int
+
+	// SkippedPrefixes is number of prefixes skipped because they had no
+	// origin AS or could not be parsed
+	SkippedPrefixes int
+}
+
+// NewMRTReader returns MRTReader reading from "r". Compression is
+// detected from first bytes of input.
+func NewMRTReader(r io.Reader) (*MRTReader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(3)
+
+	if len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(gz)
+	} else if string(magic) == "BZh" {
+		br = bufio.NewReader(bzip2.NewReader(br))
+	}
+
+	return &MRTReader{r: br}, nil
+}
+
+// Next returns next route. One route is returned for every distinct origin
+// of a prefix. It returns io.EOF when there are no more routes.
+func (m *MRTReader) Next() (IPAddress, error) {
+	for len(m.pending) == 0 {
+		if err := m.readRecord(); err != nil {
+			return nil, err
+		}
+	}
+
+	ip := m.pending[0]
+	m.pending = m.pending[1:]
+	m.Routes++
+	if len(ip.GetASSet()) > 0 {
+		m.ASSetRoutes++
+	}
+
+	return ip, nil
+}
+
+// readRecord reads one MRT record and queues routes of RIB records
+func (m *MRTReader) readRecord() error {
+	header := make([]byte, mrtHeaderLen)
+	if _, err := io.ReadFull(m.r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return ErrInvalidMRT
+		}
+		return err
+	}
+
+	mrtType := binary.BigEndian.Uint16(header[4:6])
+	subtype := binary.BigEndian.Uint16(header[6:8])
+	length := binary.BigEndian.Uint32(header[8:12])
+	m.records++
+
+	// Length comes from input, so corrupt header must not allocate
+	// gigabytes
+	if length > mrtMaxRecordLen {
+		return ErrInvalidMRT
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(m.r, body); err != nil {
+		return ErrInvalidMRT
+	}
+
+	if mrtType != mrtTypeTableDumpV2 {
+		return nil
+	}
+
+	switch subtype {
+	case mrtSubtypeRIBIPv4:
+		return m.parseRIB(body, 32)
+	case mrtSubtypeRIBIPv6:
+		return m.parseRIB(body, 128)
+	}
+
+	return nil
+}
+
+// parseRIB parses RIB_IPV4_UNICAST or RIB_IPV6_UNICAST record body and
+// queues one route per distinct origin of the prefix
+func (m *MRTReader) parseRIB(body []byte, numBits int) error {
+	// Sequence number (4), prefix length (1), prefix, entry count (2)
+	if len(body) < 5 {
+		return ErrInvalidMRT
+	}
+
+	prefixLen := int(body[4])
+	prefixBytes := (prefixLen + 7) / 8
+	if prefixLen > numBits || len(body) < 5+prefixBytes+2 {
+		return ErrInvalidMRT
+	}
+
+	ipCidr := mrtPrefixString(body[5:5+prefixBytes], prefixLen, numBits)
+	pos := 5 + prefixBytes
+	entryCount := int(binary.BigEndian.Uint16(body[pos : pos+2]))
+	pos += 2
+
+	origins := []mrtRouteOrigin{}
+	seen := map[string]bool{}
+	for i := 0; i < entryCount; i++ {
+		// Peer index (2), originated time (4), attribute length (2)
+		if len(body) < pos+8 {
+			return ErrInvalidMRT
+		}
+
+		attrLen := int(binary.BigEndian.Uint16(body[pos+6 : pos+8]))
+		pos += 8
+		if len(body) < pos+attrLen {
+			return ErrInvalidMRT
+		}
+
+		origin, err := mrtOrigin(body[pos : pos+attrLen])
+		if err != nil {
+			return err
+		}
+		pos += attrLen
+
+		key := fmt.Sprint(origin.asSet, origin.asns)
+		if origin.asns != nil && !seen[key] {
+			seen[key] = true
+			origins = append(origins, origin)
+		}
+	}
+
+	if len(origins) == 0 {
+		return m.skipPrefix(ipCidr, ErrMRTNoOrigin)
+	}
+
+	for _, origin := range origins {
+		ipAddress, err := newRouteAddress(ipCidr, origin.asns[0])
+		if err != nil {
+			return m.skipPrefix(ipCidr, err)
+		}
+
+		if origin.asSet {
+			ipAddress = setASSet(ipAddress, origin.asns)
+		}
+		m.pending = append(m.pending, ipAddress)
+	}
+
+	return nil
+}
+
+// skipPrefix counts skipped prefix and reports it to m.skip
+func (m *MRTReader) skipPrefix(prefix string, err error) error {
+	m.SkippedPrefixes++
+	if m.skip == nil {
+		return nil
+	}
+
+	return m.skip(m.records, prefix, err)
+}
+
+// mrtRouteOrigin is origin of one RIB entry. When AS_PATH ends with
+// AS_SET, asSet is set and asns holds all members of the set.
+type mrtRouteOrigin struct {
+	asns  []int
+	asSet bool
+}
+
+// mrtOrigin returns origin of a RIB entry from its BGP path attributes.
+// Origin is the last AS of AS_SEQUENCE, or all members of AS_SET when
+// AS_PATH ends with AS_SET. Origin has no ASNs if AS_PATH is missing or
+// empty.
+func mrtOrigin(attrs []byte) (mrtRouteOrigin, error) {
+	pos := 0
+	for pos < len(attrs) {
+		// Flags (1), type (1), length (1 or 2)
+		if len(attrs) < pos+3 {
+			return mrtRouteOrigin{}, ErrInvalidMRT
+		}
+
+		flags := attrs[pos]
+		attrType := attrs[pos+1]
+		attrLen := int(attrs[pos+2])
+		pos += 3
+		if flags&bgpAttrFlagExtendedLen != 0 {
+			if len(attrs) < pos+1 {
+				return mrtRouteOrigin{}, ErrInvalidMRT
+			}
+			attrLen = attrLen<<8 | int(attrs[pos])
+			pos++
+		}
+
+		if len(attrs) < pos+attrLen {
+			return mrtRouteOrigin{}, ErrInvalidMRT
+		}
+
+		if attrType == bgpAttrTypeASPath {
+			return asPathOrigin(attrs[pos : pos+attrLen])
+		}
+		pos += attrLen
+	}
+
+	return mrtRouteOrigin{}, nil
+}
+
+// asPathOrigin returns origin from AS_PATH attribute value. AS numbers
+// are always 4 bytes in TABLE_DUMP_V2. Confederation segments are ignored.
+func asPathOrigin(path []byte) (mrtRouteOrigin, error) {
+	var origin mrtRouteOrigin
+	pos := 0
+	for pos < len(path) {
+		// Segment type (1), number of ASes (1), ASes (4 each)
+		if len(path) < pos+2 {
+			return mrtRouteOrigin{}, ErrInvalidMRT
+		}
+
+		segType := path[pos]
+		count := int(path[pos+1])
+		pos += 2
+		if len(path) < pos+4*count {
+			return mrtRouteOrigin{}, ErrInvalidMRT
+		}
+
+		asns := []int{}
+		for i := 0; i < count; i++ {
+			asns = append(asns, int(binary.BigEndian.Uint32(path[pos:pos+4])))
+			pos += 4
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		switch segType {
+		case asPathSegmentASSeq:
+			origin = mrtRouteOrigin{asns: asns[count-1:]}
+		case asPathSegmentASSet:
+			origin = mrtRouteOrigin{asns: asns, asSet: true}
+		}
+	}
+
+	return origin, nil
+}
+
+// mrtPrefixString returns "<address>/<length>" for prefix bytes of RIB
+// record. Trailing bytes of the address which are not present are zero.
+func mrtPrefixString(prefix []byte, prefixLen int, numBits int) string {
+	addr := make([]byte, numBits/8)
+	copy(addr, prefix)
+
+	parts := []string{}
+	if numBits == 32 {
+		for _, b := range addr {
+			parts = append(parts, fmt.Sprint(b))
+		}
+		return fmt.Sprintf("%s/%d", strings.Join(parts, "."), prefixLen)
+	}
+
+	for i := 0; i < len(addr); i += 2 {
+		parts = append(parts, fmt.Sprintf("%x", binary.BigEndian.Uint16(addr[i:i+2])))
+	}
+	return fmt.Sprintf("%s/%d", strings.Join(parts, ":"), prefixLen)
+}
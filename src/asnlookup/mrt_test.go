@@ -0,0 +1,261 @@
+package asnlookup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// mrtRecord returns MRT record with TABLE_DUMP_V2 type, "subtype" and "body"
+func mrtRecord(subtype uint16, body []byte) []byte {
+	record := make([]byte, mrtHeaderLen)
+	binary.BigEndian.PutUint16(record[4:6], mrtTypeTableDumpV2)
+	binary.BigEndian.PutUint16(record[6:8], subtype)
+	binary.BigEndian.PutUint32(record[8:12], uint32(len(body)))
+	return append(record, body...)
+}
+
+// mrtRIB returns RIB record body for prefix bytes "prefix" of "prefixLen"
+// bits with one entry for each AS_PATH attribute in "paths"
+func mrtRIB(prefix []byte, prefixLen int, paths ...[]byte) []byte {
+	body := []byte{0, 0, 0, 1, byte(prefixLen)}
+	body = append(body, prefix...)
+	body = append(body, byte(len(paths)>>8), byte(len(paths)))
+
+	for i, path := range paths {
+		// ORIGIN attribute followed by AS_PATH attribute with extended length
+		attrs := []byte{0x40, 1, 1, 0}
+		attrs = append(attrs, 0x50, bgpAttrTypeASPath, byte(len(path)>>8), byte(len(path)))
+		attrs = append(attrs, path...)
+
+		body = append(body, 0, byte(i), 0, 0, 0, 0, byte(len(attrs)>>8), byte(len(attrs)))
+		body = append(body, attrs...)
+	}
+
+	return body
+}
+
+// asPathSegment returns AS_PATH segment of "segType" with 4 byte "asns"
+func asPathSegment(segType byte, asns ...uint32) []byte {
+	segment := []byte{segType, byte(len(asns))}
+	for _, asn := range asns {
+		segment = append(segment, byte(asn>>24), byte(asn>>16), byte(asn>>8), byte(asn))
+	}
+
+	return segment
+}
+
+// mrtTestDump returns MRT RIB dump used by tests. It is same dump as
+// ./testdata/rib.mrt.bz2.
+func mrtTestDump() []byte {
+	dump := []byte{}
+
+	// Peer index table is skipped by reader
+	dump = append(dump, mrtRecord(1, []byte{0, 0, 0, 0, 0, 0, 0, 0})...)
+
+	// Two peers see same origin, third one different origin
+	dump = append(dump, mrtRecord(mrtSubtypeRIBIPv4, mrtRIB([]byte{8, 8, 8}, 24,
+		asPathSegment(asPathSegmentASSeq, 3356, 15169),
+		asPathSegment(asPathSegmentASSeq, 174, 15169),
+		asPathSegment(asPathSegmentASSeq, 174, 36040),
+	))...)
+
+	// AS_PATH ending with AS_SET
+	dump = append(dump, mrtRecord(mrtSubtypeRIBIPv4, mrtRIB([]byte{192, 121, 43}, 24,
+		append(asPathSegment(asPathSegmentASSeq, 3356), asPathSegment(asPathSegmentASSet, 64501, 64502)...),
+	))...)
+
+	// Empty AS_PATH of locally originated route has no origin
+	dump = append(dump, mrtRecord(mrtSubtypeRIBIPv4, mrtRIB([]byte{10}, 8, []byte{}))...)
+
+	dump = append(dump, mrtRecord(mrtSubtypeRIBIPv6, mrtRIB([]byte{0x20, 0x01, 0x0d, 0xb8}, 32,
+		asPathSegment(asPathSegmentASSeq, 6939, 4200000000),
+	))...)
+
+	return dump
+}
+
+func TestMRTReader(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write(mrtTestDump())
+	gz.Close()
+
+	bzipped, err := ioutil.ReadFile("./testdata/rib.mrt.bz2")
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	want := []NodeInfo{
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 36040},
+		{Subnet: "192.121.43.0", Cidr: 24, Asn: 64501, ASSet: []ASN{64501, 64502}},
+		{Subnet: "2001:db8::", Cidr: 32, Asn: 4200000000},
+	}
+
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Read Uncompressed Dump",
+			input: mrtTestDump(),
+		},
+		{
+			name:  "Read Gzip Compressed Dump",
+			input: gzipped.Bytes(),
+		},
+		{
+			name:  "Read Bzip2 Compressed Dump",
+			input: bzipped,
+		},
+	}
+
+	for _, testCase := range testCases {
+		mrt, err := NewMRTReader(bytes.NewReader(testCase.input))
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		got := []NodeInfo{}
+		for {
+			ip, err := mrt.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+			}
+			got = append(got, newNodeInfo(ip))
+		}
+
+		if reflect.DeepEqual(got, want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, want)
+		}
+
+		if mrt.Routes != 4 || mrt.ASSetRoutes != 1 || mrt.SkippedPrefixes != 1 {
+			t.Fatalf("%s: counts do not match: got %v/%v/%v, want 4/1/1", testCase.name, mrt.Routes, mrt.ASSetRoutes, mrt.SkippedPrefixes)
+		}
+	}
+}
+
+func TestMRTReaderSingleMemberASSet(t *testing.T) {
+	dump := mrtRecord(mrtSubtypeRIBIPv4, mrtRIB([]byte{192, 121, 43}, 24,
+		append(asPathSegment(asPathSegmentASSeq, 3356), asPathSegment(asPathSegmentASSet, 64501)...),
+		asPathSegment(asPathSegmentASSeq, 3356, 64501),
+	))
+
+	mrt, err := NewMRTReader(bytes.NewReader(dump))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	got := []NodeInfo{}
+	for {
+		ip, err := mrt.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("received error does not match: got %v, want %v", err, nil)
+		}
+		got = append(got, newNodeInfo(ip))
+	}
+
+	// AS_SET with one member is a different origin than the same AS
+	want := []NodeInfo{
+		{Subnet: "192.121.43.0", Cidr: 24, Asn: 64501, ASSet: []ASN{64501}},
+		{Subnet: "192.121.43.0", Cidr: 24, Asn: 64501},
+	}
+	if reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+
+	if mrt.ASSetRoutes != 1 {
+		t.Fatalf("AS_SET routes do not match: got %v, want %v", mrt.ASSetRoutes, 1)
+	}
+}
+
+func TestMRTReaderTruncated(t *testing.T) {
+	dump := mrtTestDump()
+
+	// Header of record longer than maximum record length
+	tooLong := mrtRecord(mrtSubtypeRIBIPv4, nil)
+	binary.BigEndian.PutUint32(tooLong[8:12], mrtMaxRecordLen+1)
+
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Read Truncated Header",
+			input: dump[:mrtHeaderLen-2],
+		},
+		{
+			name:  "Read Truncated Record",
+			input: dump[:len(dump)-3],
+		},
+		{
+			name:  "Read Too Long Record",
+			input: tooLong,
+		},
+		{
+			name:  "Read Record With Bad Entry Count",
+			input: mrtRecord(mrtSubtypeRIBIPv4, []byte{0, 0, 0, 1, 8, 10, 0, 5}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		mrt, err := NewMRTReader(bytes.NewReader(testCase.input))
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		for err == nil {
+			_, err = mrt.Next()
+		}
+
+		if err != ErrInvalidMRT {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, ErrInvalidMRT)
+		}
+	}
+}
+
+func TestTableFormatMRT(t *testing.T) {
+	table, err := NewTable(bytes.NewReader(mrtTestDump()), WithTableFormat(TableFormatMRT))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	got, err := table.Lookup("8.8.8.8")
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	want := NodeInfoList{
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 36040},
+	}
+	if reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+
+	status := table.Status()
+	if status.Routes != 4 || status.ASSetRoutes != 1 {
+		t.Fatalf("routes do not match: got %v/%v, want 4/1", status.Routes, status.ASSetRoutes)
+	}
+
+	wantSkipped := map[string]int{ErrMRTNoOrigin.Error(): 1}
+	if reflect.DeepEqual(status.Skipped, wantSkipped) != true {
+		t.Fatalf("skipped prefixes do not match: got %v, want %v", status.Skipped, wantSkipped)
+	}
+
+	// Prefix without origin is in fourth record, after peer index table
+	_, err = NewTable(bytes.NewReader(mrtTestDump()), WithTableFormat(TableFormatMRT), WithStrict())
+	pe, ok := err.(*ParseError)
+	if !ok || pe.Err != ErrMRTNoOrigin || pe.Line != 4 || pe.Text != "10.0.0.0/8" {
+		t.Fatalf("received error does not match: got %v, want %v", err, &ParseError{Line: 4, Text: "10.0.0.0/8", Err: ErrMRTNoOrigin})
+	}
+}
@@ -0,0 +1,81 @@
+package asnlookup
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPfx2asOrigin is returned when origin field of pfx2as line is
+// badly formatted
+var ErrInvalidPfx2asOrigin = errors.New("Invalid pfx2as origin")
+
+// parsePfx2asLine parses one line of CAIDA Routeviews prefix to AS
+// (pfx2as) dataset. Line is "<address>\t<length>\t<origin>". Origin is
+// one ASN, multiple origin ASNs (MOAS) separated by "_", or AS_SET in
+// "{1,2,3}" or "1,2,3" form. MOAS and AS_SET can be combined, e.g.
+// "1_{2,3}".
+// One route is returned for every origin of a MOAS prefix, so no origin
+// is lost. Route with AS_SET origin keeps all members of the set.
+func parsePfx2asLine(line string) ([]IPAddress, error) {
+	parts := strings.Split(line, "\t")
+	if len(parts) != 3 {
+		return nil, ErrInvalidTableLine
+	}
+
+	ipCidr := parts[0] + "/" + parts[1]
+	routes := []IPAddress{}
+	for _, origin := range strings.Split(parts[2], "_") {
+		asSet, err := parsePfx2asOrigin(origin)
+		if err != nil {
+			return nil, err
+		}
+
+		ipAddress, err := newRouteAddress(ipCidr, asSet[0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Only AS_SET origin keeps members of the set. Braces are optional,
+		// so any origin with more than one member is an AS_SET.
+		if len(asSet) > 1 || strings.HasPrefix(origin, "{") {
+			ipAddress = setASSet(ipAddress, asSet)
+		}
+
+		routes = append(routes, ipAddress)
+	}
+
+	return routes, nil
+}
+
+// parsePfx2asOrigin parses one origin, "123", "{1,2,3}" or "1,2,3", and
+// returns its ASNs
+func parsePfx2asOrigin(origin string) ([]int, error) {
+	if strings.HasPrefix(origin, "{") && strings.HasSuffix(origin, "}") {
+		origin = origin[1 : len(origin)-1]
+	}
+
+	asSet := []int{}
+	for _, member := range strings.Split(origin, ",") {
+		asn, err := ParseASN(member)
+		if err != nil {
+			return nil, ErrInvalidPfx2asOrigin
+		}
+		asSet = append(asSet, int(asn))
+	}
+
+	return asSet, nil
+}
+
+// setASSet returns copy of "ip" with AS_SET origin "asSet"
+func setASSet(ip IPAddress, asSet []int) IPAddress {
+	switch v := ip.(type) {
+	case IPv4Address:
+		v.asSet = asSet
+		return v
+	case IPv6Address:
+		v.asSet = asSet
+		return v
+	}
+
+	return ip
+}
@@ -0,0 +1,111 @@
+package asnlookup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePfx2asLine(t *testing.T) {
+	testCases := []struct {
+		name string
+		line string
+		want []NodeInfo
+		err  error
+	}{
+		{
+			name: "Parse Single Origin",
+			line: "1.0.0.0\t24\t13335",
+			want: []NodeInfo{
+				{Subnet: "1.0.0.0", Cidr: 24, Asn: 13335},
+			},
+			err: nil,
+		},
+		{
+			name: "Parse Multiple Origins",
+			line: "8.8.8.0\t24\t15169_36040",
+			want: []NodeInfo{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 36040},
+			},
+			err: nil,
+		},
+		{
+			name: "Parse AS Set Origin",
+			line: "192.121.43.0\t24\t{1,2,3}",
+			want: []NodeInfo{
+				{Subnet: "192.121.43.0", Cidr: 24, Asn: 1, ASSet: []ASN{1, 2, 3}},
+			},
+			err: nil,
+		},
+		{
+			name: "Parse AS Set Origin Without Braces",
+			line: "1.0.0.0\t24\t13335,4200",
+			want: []NodeInfo{
+				{Subnet: "1.0.0.0", Cidr: 24, Asn: 13335, ASSet: []ASN{13335, 4200}},
+			},
+			err: nil,
+		},
+		{
+			name: "Parse Multiple Origins With AS Set",
+			line: "2001:db8::\t32\t64500_{64501,64502}",
+			want: []NodeInfo{
+				{Subnet: "2001:db8::", Cidr: 32, Asn: 64500},
+				{Subnet: "2001:db8::", Cidr: 32, Asn: 64501, ASSet: []ASN{64501, 64502}},
+			},
+			err: nil,
+		},
+		{
+			name: "Parse Invalid Origin",
+			line: "1.0.0.0\t24\t{1,x}",
+			want: nil,
+			err:  ErrInvalidPfx2asOrigin,
+		},
+		{
+			name: "Parse Line With Wrong Number Of Fields",
+			line: "1.0.0.0/24 13335",
+			want: nil,
+			err:  ErrInvalidTableLine,
+		},
+	}
+
+	for _, testCase := range testCases {
+		routes, err := parsePfx2asLine(testCase.line)
+		if err != testCase.err {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+		}
+
+		var got []NodeInfo
+		for _, ipAddress := range routes {
+			got = append(got, newNodeInfo(ipAddress))
+		}
+
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestTablePfx2as(t *testing.T) {
+	text := "8.0.0.0\t9\t3356\n8.8.8.0\t24\t15169_36040\n8.8.8.0\t23\t{64500,64501}\n"
+	table, err := NewTable(strings.NewReader(text), WithTableFormat(TableFormatPfx2as))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	want := NodeInfoList{
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 36040},
+		{Subnet: "8.8.8.0", Cidr: 23, Asn: 64500, ASSet: []ASN{64500, 64501}},
+		{Subnet: "8.0.0.0", Cidr: 9, Asn: 3356},
+	}
+
+	got, _ := table.Lookup("8.8.8.8")
+	if reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+
+	if len(table.Prefixes(64501)) != 0 || len(table.Prefixes(36040)) != 1 {
+		t.Fatalf("result does not match: got %v & %v, want no prefix of AS_SET & one prefix", table.Prefixes(64501), table.Prefixes(36040))
+	}
+}
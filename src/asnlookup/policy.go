@@ -0,0 +1,114 @@
+package asnlookup
+
+import "errors"
+
+// InsertPolicy decides what Insert does when trie already has routes with
+// same prefix as the inserted route
+type InsertPolicy string
+
+// Insert policies supported by Trie and RadixTrie
+const (
+	// InsertKeepAll keeps every origin of the prefix, but inserting a
+	// route with same origin again does nothing. This is the default
+	// (also for empty policy).
+	InsertKeepAll InsertPolicy = "keep-all"
+
+	// InsertKeepFirst keeps only the first route inserted for the prefix
+	InsertKeepFirst InsertPolicy = "keep-first"
+
+	// InsertReplace keeps only the last route inserted for the prefix
+	InsertReplace InsertPolicy = "replace"
+)
+
+// ErrUnknownInsertPolicy is returned when insert policy is not supported
+var ErrUnknownInsertPolicy = errors.New("Unknown insert policy, use keep-all, keep-first or replace")
+
+// valid returns true if "p" is a supported insert policy
+func (p InsertPolicy) valid() bool {
+	return p == "" || p == InsertKeepAll || p == InsertKeepFirst || p == InsertReplace
+}
+
+// WithInsertPolicy sets how routes with same prefix are stored. By default
+// InsertKeepAll is used, so duplicate lines in a table are stored once.
+func WithInsertPolicy(policy InsertPolicy) Option {
+	return func(o *tableOptions) {
+		o.insertPolicy = policy
+	}
+}
+
+// insertInfo adds "info" to "infos" of one trie node according to
+// "policy". Infos are kept sorted by origin, see compareOrigin. It returns
+// new infos, false if "info" was not stored, and infos replaced by it.
+func insertInfo(infos []NodeInfo, info NodeInfo, policy InsertPolicy) ([]NodeInfo, bool, NodeInfoList) {
+	switch policy {
+	case InsertKeepFirst:
+		if len(infos) > 0 {
+			return infos, false, nil
+		}
+	case InsertReplace:
+		if len(infos) > 0 {
+			return []NodeInfo{info}, true, NodeInfoList(infos)
+		}
+	}
+
+	i := 0
+	for ; i < len(infos); i++ {
+		c := compareOrigin(infos[i], info)
+		if c == 0 {
+			return infos, false, nil
+		} else if c > 0 {
+			break
+		}
+	}
+
+	infos = append(infos, NodeInfo{})
+	copy(infos[i+1:], infos[i:])
+	infos[i] = info
+	return infos, true, nil
+}
+
+// removeInfo removes infos with origin "asn" (first member for AS_SET
+// origin) from "infos" of one trie node. It returns remaining infos and
+// removed infos.
+func removeInfo(infos []NodeInfo, asn ASN) ([]NodeInfo, NodeInfoList) {
+	var removed NodeInfoList
+	kept := infos[:0]
+	for _, info := range infos {
+		if info.Asn != asn {
+			kept = append(kept, info)
+		} else {
+			removed = append(removed, info)
+		}
+	}
+
+	return kept, removed
+}
+
+// compareOrigin orders routes with same prefix by origin ASN. Single ASN
+// origin comes before AS_SET origin with same first member, and AS_SETs
+// are compared member by member. It returns -1, 0 or 1.
+func compareOrigin(a, b NodeInfo) int {
+	if a.Asn != b.Asn {
+		if a.Asn < b.Asn {
+			return -1
+		}
+		return 1
+	}
+
+	for i := 0; i < len(a.ASSet) && i < len(b.ASSet); i++ {
+		if a.ASSet[i] != b.ASSet[i] {
+			if a.ASSet[i] < b.ASSet[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(a.ASSet) < len(b.ASSet):
+		return -1
+	case len(a.ASSet) > len(b.ASSet):
+		return 1
+	}
+	return 0
+}
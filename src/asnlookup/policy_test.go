@@ -0,0 +1,239 @@
+package asnlookup
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestInsertPolicy(t *testing.T) {
+	testCases := []struct {
+		name       string
+		policy     InsertPolicy
+		ipCidrList []ipCidrAsn
+		want       NodeInfoList
+		stored     []bool
+	}{
+		{
+			name:   "Keep All Deduplicates Same Origin",
+			policy: InsertKeepAll,
+			ipCidrList: []ipCidrAsn{
+				{"8.8.8.0/24", 15169},
+				{"8.8.8.0/24", 15169},
+				{"8.8.8.0/24", 350},
+			},
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+			},
+			stored: []bool{true, false, true},
+		},
+		{
+			name:   "Empty Policy Keeps All",
+			policy: "",
+			ipCidrList: []ipCidrAsn{
+				{"8.8.8.0/24", 15169},
+				{"8.8.8.0/24", 15169},
+			},
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+			},
+			stored: []bool{true, false},
+		},
+		{
+			name:   "Keep First",
+			policy: InsertKeepFirst,
+			ipCidrList: []ipCidrAsn{
+				{"8.8.8.0/24", 15169},
+				{"8.8.8.0/24", 350},
+				{"8.0.0.0/9", 352},
+			},
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+			stored: []bool{true, false, true},
+		},
+		{
+			name:   "Replace",
+			policy: InsertReplace,
+			ipCidrList: []ipCidrAsn{
+				{"8.8.8.0/24", 15169},
+				{"8.8.8.0/24", 350},
+				{"8.0.0.0/9", 352},
+			},
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+			stored: []bool{true, true, true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, trie := range []RouteTrie{&Trie{Root4: NewNode(), Root6: NewNode(), Policy: testCase.policy},
+			&RadixTrie{Root4: &RadixNode{}, Root6: &RadixNode{}, Policy: testCase.policy}} {
+			for i, ipCidr := range testCase.ipCidrList {
+				ipAddress, err := newTestIPAddress(ipCidr.ip, ipCidr.asn)
+				if err != nil {
+					t.Fatalf("%s: received error for %s/%d does not match: got %v, want %v", testCase.name, ipCidr.ip, ipCidr.asn, err, nil)
+				}
+
+				stored, _ := trie.Insert(ipAddress)
+				if stored != testCase.stored[i] {
+					t.Fatalf("%s: %T: stored flag of %s/%d does not match: got %v, want %v", testCase.name, trie, ipCidr.ip, ipCidr.asn, stored, testCase.stored[i])
+				}
+			}
+
+			ipToFind, _ := newIPToFind("8.8.8.8")
+			got := trie.Find(ipToFind)
+			if reflect.DeepEqual(got, testCase.want) != true {
+				t.Fatalf("%s: %T: result does not match: got %v, want %v", testCase.name, trie, got, testCase.want)
+			}
+		}
+	}
+}
+
+func TestInsertOrderDoesNotChangeResult(t *testing.T) {
+	routes := []ipCidrAsn{
+		{"8.0.0.0/9", 352},
+		{"8.8.8.0/24", 15169},
+		{"8.8.8.0/24", 350},
+		{"8.8.8.0/24", 3356},
+		{"8.0.0.0/9", 351},
+	}
+	want := NodeInfoList{
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 3356},
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+		{Subnet: "8.0.0.0", Cidr: 9, Asn: 351},
+		{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+	}
+
+	for _, reversed := range []bool{false, true} {
+		for _, trie := range []RouteTrie{NewTrie(), NewRadixTrie()} {
+			for i := range routes {
+				route := routes[i]
+				if reversed {
+					route = routes[len(routes)-1-i]
+				}
+				ipAddress, _ := newIPv4Address(route.ip, route.asn)
+				trie.Insert(ipAddress)
+			}
+
+			ipToFind, _ := newIPToFind("8.8.8.8")
+			got := trie.Find(ipToFind)
+			if reflect.DeepEqual(got, want) != true {
+				t.Fatalf("%T: result does not match: got %v, want %v", trie, got, want)
+			}
+
+			longest, _ := trie.LongestMatch(ipToFind)
+			if reflect.DeepEqual(longest, want[0]) != true {
+				t.Fatalf("%T: longest match does not match: got %v, want %v", trie, longest, want[0])
+			}
+		}
+	}
+}
+
+func TestCompareOrigin(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    NodeInfo
+		b    NodeInfo
+		want int
+	}{
+		{
+			name: "Lower ASN First",
+			a:    NodeInfo{Asn: 100},
+			b:    NodeInfo{Asn: 200},
+			want: -1,
+		},
+		{
+			name: "Same ASN",
+			a:    NodeInfo{Asn: 100},
+			b:    NodeInfo{Asn: 100},
+			want: 0,
+		},
+		{
+			name: "Single ASN Before AS_SET",
+			a:    NodeInfo{Asn: 100, ASSet: []ASN{100, 200}},
+			b:    NodeInfo{Asn: 100},
+			want: 1,
+		},
+		{
+			name: "AS_SETs Compared By Members",
+			a:    NodeInfo{Asn: 100, ASSet: []ASN{100, 200}},
+			b:    NodeInfo{Asn: 100, ASSet: []ASN{100, 300}},
+			want: -1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := compareOrigin(testCase.a, testCase.b)
+		if got != testCase.want {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestTableInsertPolicy(t *testing.T) {
+	const tableText = `8.8.8.0/24 15169
+8.8.8.0/24 15169
+8.8.8.0/24 350
+8.8.4.0/24 15169
+`
+
+	testCases := []struct {
+		name     string
+		policy   InsertPolicy
+		routes   int
+		prefixes []string
+		err      error
+	}{
+		{
+			name:     "Table With Keep All Policy",
+			policy:   InsertKeepAll,
+			routes:   3,
+			prefixes: []string{"8.8.4.0/24", "8.8.8.0/24"},
+			err:      nil,
+		},
+		{
+			name:     "Table With Replace Policy",
+			policy:   InsertReplace,
+			routes:   2,
+			prefixes: []string{"8.8.4.0/24"},
+			err:      nil,
+		},
+		{
+			name:     "Table With Unknown Policy",
+			policy:   "keep-last",
+			routes:   0,
+			prefixes: nil,
+			err:      ErrUnknownInsertPolicy,
+		},
+	}
+
+	for _, testCase := range testCases {
+		table, err := NewTable(strings.NewReader(tableText), WithInsertPolicy(testCase.policy))
+		if err != testCase.err {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if table.Status().Routes != testCase.routes {
+			t.Fatalf("%s: number of routes does not match: got %v, want %v", testCase.name, table.Status().Routes, testCase.routes)
+		}
+
+		prefixes := []string{}
+		for _, ip := range table.Prefixes(15169) {
+			prefixes = append(prefixes, ip.GetString()+"/"+strconv.Itoa(ip.GetCidrLen()))
+		}
+		if reflect.DeepEqual(prefixes, testCase.prefixes) != true {
+			t.Fatalf("%s: prefixes do not match: got %v, want %v", testCase.name, prefixes, testCase.prefixes)
+		}
+	}
+}
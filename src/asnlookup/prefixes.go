@@ -0,0 +1,148 @@
+package asnlookup
+
+import (
+	"math/big"
+	"sort"
+)
+
+// PrefixList holds prefixes (IPv4 and IPv6 addresses with CIDR prefix
+// length) sorted by address. IPv4 prefixes come before IPv6 prefixes
+// and shorter prefix comes first for same address.
+type PrefixList []IPAddress
+
+// Len implements Len() method for sort interface
+func (p PrefixList) Len() int {
+	return len(p)
+}
+
+// Less implements Less() method for sort interface
+func (p PrefixList) Less(i, j int) bool {
+	if p[i].GetNumBitsInAddress() != p[j].GetNumBitsInAddress() {
+		return p[i].GetNumBitsInAddress() < p[j].GetNumBitsInAddress()
+	}
+
+	ki, kj := ipKey(p[i]), ipKey(p[j])
+	if ki[0] != kj[0] {
+		return ki[0] < kj[0]
+	}
+	if ki[1] != kj[1] {
+		return ki[1] < kj[1]
+	}
+
+	return p[i].GetCidrLen() < p[j].GetCidrLen()
+}
+
+// Swap implements Swap() method for sort interface
+func (p PrefixList) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+// PrefixSummary holds number of prefixes and number of addresses covered
+// by them for each address type. Addresses covered by more than one
+// prefix are counted only once.
+type PrefixSummary struct {
+	IPv4Prefixes  int
+	IPv4Addresses *big.Int
+	IPv6Prefixes  int
+	IPv6Addresses *big.Int
+}
+
+// asnIndex is secondary index from ASN to prefixes originated by it.
+// It is built alongside the trie while table is parsed.
+type asnIndex map[ASN]PrefixList
+
+// add adds prefix "ip" to index under its ASN. Prefix with AS_SET
+// origin is not added: AS_SET comes from aggregation, so none of its
+// members is known to originate the prefix.
+func (idx asnIndex) add(ip IPAddress) {
+	if len(ip.GetASSet()) > 0 {
+		return
+	}
+
+	asn := ASN(ip.GetAsn())
+	idx[asn] = append(idx[asn], ip)
+}
+
+// remove removes prefix of route "info" from index under its ASN
+func (idx asnIndex) remove(info NodeInfo) {
+	if len(info.ASSet) > 0 {
+		return
+	}
+
+	prefixes := idx[info.Asn][:0]
+	for _, ip := range idx[info.Asn] {
+		if ip.GetCidrLen() != info.Cidr || ip.GetString() != info.Subnet {
+			prefixes = append(prefixes, ip)
+		}
+	}
+
+	if len(prefixes) == 0 {
+		delete(idx, info.Asn)
+	} else {
+		idx[info.Asn] = prefixes
+	}
+}
+
+// Prefixes returns all prefixes originated by "asn" sorted by address.
+// Prefix which appears more than once in table is returned only once.
+// Prefixes with AS_SET origin are not returned for members of the set.
+func (t *Table) Prefixes(asn ASN) PrefixList {
+	data := t.rlock()
+	indexed := data.index[asn]
+	sorted := make(PrefixList, len(indexed))
+	copy(sorted, indexed)
+	data.mu.RUnlock()
+	sort.Sort(sorted)
+
+	prefixes := PrefixList{}
+	for i, ip := range sorted {
+		if i > 0 && ip.GetCidrLen() == sorted[i-1].GetCidrLen() && covers(sorted[i-1], ip) {
+			continue
+		}
+		prefixes = append(prefixes, ip)
+	}
+
+	return prefixes
+}
+
+// Summary returns number of prefixes and total address space covered by
+// prefixes in sorted PrefixList "p"
+func (p PrefixList) Summary() PrefixSummary {
+	summary := PrefixSummary{
+		IPv4Addresses: big.NewInt(0),
+		IPv6Addresses: big.NewInt(0),
+	}
+
+	// As list is sorted by address, prefix is covered by an earlier prefix
+	// only if it is covered by the last prefix that was counted
+	var last IPAddress
+	for _, ip := range p {
+		addresses := summary.IPv4Addresses
+		if ip.GetNumBitsInAddress() == 32 {
+			summary.IPv4Prefixes++
+		} else {
+			summary.IPv6Prefixes++
+			addresses = summary.IPv6Addresses
+		}
+
+		if last != nil && covers(last, ip) {
+			continue
+		}
+
+		size := new(big.Int).Lsh(big.NewInt(1), uint(ip.GetNumBitsInAddress()-ip.GetCidrLen()))
+		addresses.Add(addresses, size)
+		last = ip
+	}
+
+	return summary
+}
+
+// covers returns true if prefix "a" contains prefix "b"
+func covers(a, b IPAddress) bool {
+	if a.GetNumBitsInAddress() != b.GetNumBitsInAddress() ||
+		a.GetCidrLen() > b.GetCidrLen() {
+		return false
+	}
+
+	return commonPrefixLen(ipKey(a), ipKey(b), a.GetCidrLen()) == a.GetCidrLen()
+}
@@ -0,0 +1,125 @@
+package asnlookup
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const prefixesTestText = `8.8.8.0/24 15169
+8.8.4.0/24 15169
+8.8.8.0/24 15169
+8.0.0.0/9 3356
+8.8.0.0/16 15169
+2001:4860::/32 15169
+2001:4860:4860::/48 15169
+2a00:1450::/32 15169
+`
+
+const prefixesASSetTestText = "8.8.8.0\t24\t15169\n8.8.0.0\t16\t{15169,3356}\n8.8.4.0\t24\t{15169}\n"
+
+func TestTablePrefixes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		table       string
+		asn         ASN
+		wantPrefix  []string
+		wantSummary PrefixSummary
+	}{
+		{
+			name: "Prefixes Of ASN With IPv4 & IPv6 Prefixes",
+			asn:  15169,
+			wantPrefix: []string{
+				"8.8.0.0/16",
+				"8.8.4.0/24",
+				"8.8.8.0/24",
+				"2001:4860::/32",
+				"2001:4860:4860::/48",
+				"2a00:1450::/32",
+			},
+			wantSummary: PrefixSummary{
+				IPv4Prefixes:  3,
+				IPv4Addresses: big.NewInt(65536),
+				IPv6Prefixes:  3,
+				IPv6Addresses: new(big.Int).Lsh(big.NewInt(1), 97),
+			},
+		},
+		{
+			name: "Prefixes Of ASN With One Prefix",
+			asn:  3356,
+			wantPrefix: []string{
+				"8.0.0.0/9",
+			},
+			wantSummary: PrefixSummary{
+				IPv4Prefixes:  1,
+				IPv4Addresses: big.NewInt(1 << 23),
+				IPv6Prefixes:  0,
+				IPv6Addresses: big.NewInt(0),
+			},
+		},
+		{
+			name:       "Prefixes Of AS_SET Member",
+			table:      prefixesASSetTestText,
+			asn:        15169,
+			wantPrefix: []string{"8.8.8.0/24"},
+			wantSummary: PrefixSummary{
+				IPv4Prefixes:  1,
+				IPv4Addresses: big.NewInt(256),
+				IPv6Prefixes:  0,
+				IPv6Addresses: big.NewInt(0),
+			},
+		},
+		{
+			name:       "Prefixes Of AS_SET Only Member",
+			table:      prefixesASSetTestText,
+			asn:        3356,
+			wantPrefix: []string{},
+			wantSummary: PrefixSummary{
+				IPv4Prefixes:  0,
+				IPv4Addresses: big.NewInt(0),
+				IPv6Prefixes:  0,
+				IPv6Addresses: big.NewInt(0),
+			},
+		},
+		{
+			name:       "Prefixes Of Unknown ASN",
+			asn:        64512,
+			wantPrefix: []string{},
+			wantSummary: PrefixSummary{
+				IPv4Prefixes:  0,
+				IPv4Addresses: big.NewInt(0),
+				IPv6Prefixes:  0,
+				IPv6Addresses: big.NewInt(0),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		text, opts := prefixesTestText, []Option{}
+		if testCase.table != "" {
+			text, opts = testCase.table, []Option{WithTableFormat(TableFormatPfx2as)}
+		}
+
+		table, err := NewTable(strings.NewReader(text), opts...)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		prefixes := table.Prefixes(testCase.asn)
+
+		got := []string{}
+		for _, ip := range prefixes {
+			got = append(got, ip.GetString()+"/"+strconv.Itoa(ip.GetCidrLen()))
+		}
+		if reflect.DeepEqual(got, testCase.wantPrefix) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.wantPrefix)
+		}
+
+		gotSummary := prefixes.Summary()
+		if reflect.DeepEqual(gotSummary, testCase.wantSummary) != true {
+			t.Fatalf("%s: summary does not match: got %v, want %v", testCase.name, gotSummary, testCase.wantSummary)
+		}
+	}
+}
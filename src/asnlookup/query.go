@@ -0,0 +1,62 @@
+package asnlookup
+
+import "errors"
+
+// QueryMode selects which routes Table.QueryPrefix returns for a prefix
+type QueryMode string
+
+// Query modes supported by Table.QueryPrefix
+const (
+	// QueryCovering returns routes which cover the prefix, including
+	// route with same prefix, most specific first. This is what Query
+	// returns.
+	QueryCovering QueryMode = "covering"
+
+	// QueryCovered returns routes which are more specific than the
+	// prefix and lie inside it, in address order
+	QueryCovered QueryMode = "covered"
+
+	// QueryExact returns routes with exactly the same prefix
+	QueryExact QueryMode = "exact"
+)
+
+// ErrUnknownQueryMode is returned when query mode is not supported
+var ErrUnknownQueryMode = errors.New("Unknown query mode, use covering, covered or exact")
+
+// QueryPrefix looks up routes related to "prefix" selected by "mode" and
+// returns them as Result. Prefix is in CIDR format (8.0.0.0/9); address
+// without prefix length is a host prefix (/32 or /128). Longest is not set
+// for QueryCovered matches, as they do not match whole prefix.
+func (t *Table) QueryPrefix(prefix string, mode QueryMode) (Result, error) {
+	if mode == QueryCovering {
+		return t.Query(prefix)
+	}
+	if mode != QueryCovered && mode != QueryExact {
+		return Result{}, ErrUnknownQueryMode
+	}
+
+	ipToFind, err := newIPToFind(prefix)
+	if err != nil {
+		return Result{}, err
+	}
+
+	data := t.rlock()
+	defer data.mu.RUnlock()
+	trie := data.trie
+	if mode == QueryExact {
+		exact := NodeInfoList{}
+		for _, info := range trie.Find(ipToFind) {
+			if info.Cidr == ipToFind.GetCidrLen() {
+				exact = append(exact, info)
+			}
+		}
+		return NewResult(prefix, exact), nil
+	}
+
+	result := NewResult(prefix, trie.FindCovered(ipToFind))
+	for i := range result.Matches {
+		result.Matches[i].Longest = false
+	}
+
+	return result, nil
+}
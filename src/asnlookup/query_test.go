@@ -0,0 +1,138 @@
+package asnlookup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const queryTestText = `8.0.0.0/9 352
+8.0.0.0/12 351
+8.8.8.0/24 350
+8.8.4.0/24 353
+8.8.8.0/24 354
+8.128.0.0/9 355
+2604:a880::/32 440
+2604:a880:2:d0::/64 444
+`
+
+func TestTableQueryPrefix(t *testing.T) {
+	testCases := []struct {
+		name   string
+		prefix string
+		mode   QueryMode
+		want   []Match
+		err    error
+	}{
+		{
+			name:   "Query Covering Routes",
+			prefix: "8.8.8.0/24",
+			mode:   QueryCovering,
+			want: []Match{
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350, Longest: true},
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 354, Longest: true},
+				{Prefix: "8.0.0.0/12", Subnet: "8.0.0.0", Cidr: 12, Asn: 351, Longest: false},
+				{Prefix: "8.0.0.0/9", Subnet: "8.0.0.0", Cidr: 9, Asn: 352, Longest: false},
+			},
+			err: nil,
+		},
+		{
+			name:   "Query Covering Routes Does Not Return More Specifics",
+			prefix: "8.0.0.0/10",
+			mode:   QueryCovering,
+			want: []Match{
+				{Prefix: "8.0.0.0/9", Subnet: "8.0.0.0", Cidr: 9, Asn: 352, Longest: true},
+			},
+			err: nil,
+		},
+		{
+			name:   "Query Covered Routes",
+			prefix: "8.0.0.0/9",
+			mode:   QueryCovered,
+			want: []Match{
+				{Prefix: "8.0.0.0/12", Subnet: "8.0.0.0", Cidr: 12, Asn: 351},
+				{Prefix: "8.8.4.0/24", Subnet: "8.8.4.0", Cidr: 24, Asn: 353},
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 354},
+			},
+			err: nil,
+		},
+		{
+			name:   "Query Covered Routes Of Prefix Without Route",
+			prefix: "8.8.0.0/16",
+			mode:   QueryCovered,
+			want: []Match{
+				{Prefix: "8.8.4.0/24", Subnet: "8.8.4.0", Cidr: 24, Asn: 353},
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 354},
+			},
+			err: nil,
+		},
+		{
+			name:   "Query Covered IPv6 Routes",
+			prefix: "2604:a880::/32",
+			mode:   QueryCovered,
+			want: []Match{
+				{Prefix: "2604:a880:2:d0::/64", Subnet: "2604:a880:2:d0::", Cidr: 64, Asn: 444},
+			},
+			err: nil,
+		},
+		{
+			name:   "Query Covered Routes Without Match",
+			prefix: "9.0.0.0/8",
+			mode:   QueryCovered,
+			want:   []Match{},
+			err:    nil,
+		},
+		{
+			name:   "Query Exact Routes",
+			prefix: "8.8.8.0/24",
+			mode:   QueryExact,
+			want: []Match{
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350, Longest: true},
+				{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 354, Longest: true},
+			},
+			err: nil,
+		},
+		{
+			name:   "Query Exact Routes Without Match",
+			prefix: "8.8.0.0/16",
+			mode:   QueryExact,
+			want:   []Match{},
+			err:    nil,
+		},
+		{
+			name:   "Query Unknown Mode",
+			prefix: "8.8.8.0/24",
+			mode:   "less-specific",
+			want:   nil,
+			err:    ErrUnknownQueryMode,
+		},
+		{
+			name:   "Query Invalid Prefix",
+			prefix: "8.8.8.0/33",
+			mode:   QueryCovered,
+			want:   nil,
+			err:    ErrInvalidInputIPAddress,
+		},
+	}
+
+	// Both trie implementations must return same results
+	for _, opts := range [][]Option{nil, {WithBinaryTrie()}} {
+		table, err := NewTable(strings.NewReader(queryTestText), opts...)
+		if err != nil {
+			t.Fatalf("received error does not match: got %v, want %v", err, nil)
+		}
+
+		for _, testCase := range testCases {
+			got, err := table.QueryPrefix(testCase.prefix, testCase.mode)
+			if err != testCase.err {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+			}
+
+			if reflect.DeepEqual(got.Matches, testCase.want) != true {
+				t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got.Matches, testCase.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package asnlookup
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// RadixNode is a path compressed trie node. It stores prefix bits
+// (key) and prefix length, so chains of single child nodes of binary
+// trie collapse into one node. Bits of key beyond length are zero.
+type RadixNode struct {
+	key    [2]uint64
+	length int
+	Info   []NodeInfo
+	Left   *RadixNode
+	Right  *RadixNode
+}
+
+// RadixTrie is path compressed (Patricia) trie. It stores same routes
+// and returns same NodeInfoList from Find() as Trie, but only creates
+// nodes where prefixes end or branch. IPv4 and IPv6 addresses are stored
+// under separate roots. Policy decides how routes with same prefix are
+// stored.
+type RadixTrie struct {
+	Root4  *RadixNode
+	Root6  *RadixNode
+	Policy InsertPolicy
+}
+
+// Compile time check to ensure RadixTrie satisfies RouteTrie interface
+var _ RouteTrie = &RadixTrie{}
+
+// NewRadixTrie creates a RadixTrie and returns its pointer
+func NewRadixTrie() *RadixTrie {
+	return &RadixTrie{
+		Root4: &RadixNode{},
+		Root6: &RadixNode{},
+	}
+}
+
+// root returns trie root for address type of "ip"
+func (t *RadixTrie) root(ip IPAddress) *RadixNode {
+	if ip.GetNumBitsInAddress() == 32 {
+		return t.Root4
+	}
+
+	return t.Root6
+}
+
+// Insert adds "ip" into the trie. Input "ip" can either be IPv4 or IPv6 address.
+// Routes with same prefix are stored according to t.Policy.
+func (t *RadixTrie) Insert(ip IPAddress) (bool, NodeInfoList) {
+	return t.insert(ip, t.Policy)
+}
+
+// Update makes "ip" the only route with its prefix, whatever the Policy
+// of the trie is. It returns routes with same prefix it replaced.
+func (t *RadixTrie) Update(ip IPAddress) NodeInfoList {
+	_, replaced := t.insert(ip, InsertReplace)
+	return replaced
+}
+
+// insert stores "ip" in the trie according to "policy"
+func (t *RadixTrie) insert(ip IPAddress, policy InsertPolicy) (bool, NodeInfoList) {
+	key := ipKey(ip)
+	length := ip.GetCidrLen()
+	info := newNodeInfo(ip)
+
+	// Invariant: key of "node" is a prefix of key being inserted
+	node := t.root(ip)
+	for {
+		if node.length == length {
+			var stored bool
+			var replaced NodeInfoList
+			node.Info, stored, replaced = insertInfo(node.Info, info, policy)
+			return stored, replaced
+		}
+
+		// Select child by first bit after prefix of current node
+		link := &node.Left
+		if keyBit(key, node.length+1) == 1 {
+			link = &node.Right
+		}
+
+		child := *link
+		if child == nil {
+			*link = &RadixNode{key: key, length: length, Info: []NodeInfo{info}}
+			return true, nil
+		}
+
+		common := commonPrefixLen(key, child.key, minInt(length, child.length))
+		if common == child.length {
+			// Child prefix covers the key. Continue below child.
+			node = child
+			continue
+		}
+
+		// Key and child diverge (or key ends) inside child prefix. Insert
+		// a new node at the point where they diverge.
+		split := &RadixNode{key: maskKey(key, common), length: common}
+		if common == length {
+			split.Info = []NodeInfo{info}
+		} else {
+			leaf := &RadixNode{key: key, length: length, Info: []NodeInfo{info}}
+			split.setChild(leaf)
+		}
+		split.setChild(child)
+		*link = split
+		return true, nil
+	}
+}
+
+// Delete removes routes with prefix of "ip" and origin "asn" (first member
+// for AS_SET origin) from the trie. Nodes left without routes are removed
+// and their only child takes their place, so the trie is same as if the
+// routes were never inserted. It returns removed routes.
+func (t *RadixTrie) Delete(ip IPAddress, asn ASN) NodeInfoList {
+	key := ipKey(ip)
+	length := ip.GetCidrLen()
+
+	// Keep parent links on the path to the prefix to prune nodes afterwards
+	var links []**RadixNode
+	node := t.root(ip)
+	for node.length < length {
+		link := &node.Left
+		if keyBit(key, node.length+1) == 1 {
+			link = &node.Right
+		}
+
+		child := *link
+		if child == nil || child.length > length ||
+			commonPrefixLen(key, child.key, child.length) != child.length {
+			return nil
+		}
+
+		links = append(links, link)
+		node = child
+	}
+
+	var removed NodeInfoList
+	if node.Info, removed = removeInfo(node.Info, asn); len(removed) == 0 {
+		return nil
+	}
+
+	if len(node.Info) > 0 {
+		return removed
+	}
+
+	// Nodes without routes only exist where prefixes branch
+	node.Info = nil
+	if len(links) == 0 {
+		return removed
+	}
+
+	link := links[len(links)-1]
+	switch {
+	case node.Left != nil && node.Right != nil:
+		return removed
+	case node.Left != nil:
+		*link = node.Left
+		return removed
+	case node.Right != nil:
+		*link = node.Right
+		return removed
+	}
+
+	// Node was a leaf. Its parent may now be a branch node with one child.
+	*link = nil
+	if len(links) < 2 {
+		return removed
+	}
+
+	parentLink := links[len(links)-2]
+	parent := *parentLink
+	if parent.Info != nil {
+		return removed
+	}
+
+	if parent.Left != nil {
+		*parentLink = parent.Left
+	} else {
+		*parentLink = parent.Right
+	}
+
+	return removed
+}
+
+// setChild attaches "child" as left or right child of "n" based on
+// first bit of child's key after prefix of "n"
+func (n *RadixNode) setChild(child *RadixNode) {
+	if keyBit(child.key, n.length+1) == 0 {
+		n.Left = child
+	} else {
+		n.Right = child
+	}
+}
+
+// Find walks down the trie along prefix of "ip" and returns NodeInfoList
+// sorted by Cidr length with matching trie nodes. These are the routes
+// which cover "ip", including route with same prefix.
+func (t *RadixTrie) Find(ip IPAddress) NodeInfoList {
+	key := ipKey(ip)
+	length := ip.GetCidrLen()
+	node := t.root(ip)
+
+	// Root holds /0 routes, which match every address
+	infoList := append(NodeInfoList{}, node.Info...)
+	for node.length < length {
+		child := node.Left
+		if keyBit(key, node.length+1) == 1 {
+			child = node.Right
+		}
+
+		// Stop when child prefix does not match target IP
+		if child == nil || child.length > length ||
+			commonPrefixLen(key, child.key, child.length) != child.length {
+			break
+		}
+
+		if len(child.Info) > 0 {
+			infoList = append(infoList, child.Info...)
+		}
+		node = child
+	}
+
+	// Return sorted infoList by Cidr length
+	sort.Sort(infoList)
+	return infoList
+}
+
+// LongestMatch returns the most specific route which covers "ip", the
+// first one in NodeInfoList order if several routes have same prefix. It returns false
+// if no route covers "ip". Unlike Find it does not allocate.
+func (t *RadixTrie) LongestMatch(ip IPAddress) (NodeInfo, bool) {
+	key := ipKey(ip)
+	length := ip.GetCidrLen()
+	node := t.root(ip)
+	best := node
+	for node.length < length {
+		child := node.Left
+		if keyBit(key, node.length+1) == 1 {
+			child = node.Right
+		}
+
+		if child == nil || child.length > length ||
+			commonPrefixLen(key, child.key, child.length) != child.length {
+			break
+		}
+
+		if len(child.Info) > 0 {
+			best = child
+		}
+		node = child
+	}
+
+	if len(best.Info) == 0 {
+		return NodeInfo{}, false
+	}
+	return best.Info[0], true
+}
+
+// FindCovered returns routes which are more specific than "ip" and lie
+// inside its prefix. Routes are in address order, shorter prefix first
+// for same address.
+func (t *RadixTrie) FindCovered(ip IPAddress) NodeInfoList {
+	infoList := NodeInfoList{}
+	key := ipKey(ip)
+	length := ip.GetCidrLen()
+	node := t.root(ip)
+
+	// Walk down to first node with prefix inside "ip" prefix
+	for node.length < length {
+		child := node.Left
+		if keyBit(key, node.length+1) == 1 {
+			child = node.Right
+		}
+
+		if child == nil {
+			return infoList
+		}
+
+		common := minInt(length, child.length)
+		if commonPrefixLen(key, child.key, common) != common {
+			return infoList
+		}
+		node = child
+	}
+
+	// Node with same prefix as "ip" holds routes which are not more specific
+	if node.length == length {
+		infoList = node.Left.appendSubtree(infoList)
+		return node.Right.appendSubtree(infoList)
+	}
+
+	return node.appendSubtree(infoList)
+}
+
+// appendSubtree appends Info of "n" and all nodes below it to "infoList"
+// in pre-order, which is address order
+func (n *RadixNode) appendSubtree(infoList NodeInfoList) NodeInfoList {
+	if n == nil {
+		return infoList
+	}
+
+	infoList = append(infoList, n.Info...)
+	infoList = n.Left.appendSubtree(infoList)
+	return n.Right.appendSubtree(infoList)
+}
+
+// Following are helper functions to work with 128 bit keys. IPv4
+// addresses use highest 32 bits of the key.
+
+// ipKey returns bits of "ip" as 128 bit key
+func ipKey(ip IPAddress) [2]uint64 {
+	switch v := ip.(type) {
+	case IPv4Address:
+		return [2]uint64{uint64(v.ip) << 32, 0}
+	case *IPv4Address:
+		return [2]uint64{uint64(v.ip) << 32, 0}
+	case IPv6Address:
+		return v.ip
+	case *IPv6Address:
+		return v.ip
+	}
+
+	var key [2]uint64
+	for i := 1; i <= ip.GetNumBitsInAddress(); i++ {
+		if ip.GetNthHighestBit(uint8(i)) == 1 {
+			key[(i-1)/64] |= 1 << uint(63-(i-1)%64)
+		}
+	}
+
+	return key
+}
+
+// keyBit returns nth highest bit of key, n starts from 1
+func keyBit(key [2]uint64, n int) uint8 {
+	if n <= 64 {
+		return uint8((key[0] >> uint(64-n)) & 0x1)
+	}
+
+	return uint8((key[1] >> uint(128-n)) & 0x1)
+}
+
+// maskKey returns key with all bits after first "length" bits set to 0
+func maskKey(key [2]uint64, length int) [2]uint64 {
+	if length <= 0 {
+		return [2]uint64{}
+	} else if length < 64 {
+		return [2]uint64{key[0] & (^uint64(0) << uint(64-length)), 0}
+	} else if length < 128 {
+		return [2]uint64{key[0], key[1] & (^uint64(0) << uint(128-length))}
+	}
+
+	return key
+}
+
+// commonPrefixLen returns number of leading bits which are same in both
+// keys, considering at most "max" bits
+func commonPrefixLen(a, b [2]uint64, max int) int {
+	common := bits.LeadingZeros64(a[0] ^ b[0])
+	if common == 64 {
+		common += bits.LeadingZeros64(a[1] ^ b[1])
+	}
+
+	return minInt(common, max)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,513 @@
+package asnlookup
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRadixTrieInsertFind(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ipCidrList []ipCidrAsn
+		ipToFind   string
+		want       NodeInfoList
+	}{
+		{
+			name: "Insert-Find IPv4 Address",
+			ipCidrList: []ipCidrAsn{
+				{"192.168.1.1/24", 351},
+				{"192.168.0.0/16", 355},
+				{"8.8.8.0/14", 450},
+				{"192.168.1.0/20", 600},
+				{"1.1.1.0/16", 200},
+			},
+			ipToFind: "192.168.1.5/32",
+			want: NodeInfoList{
+				{Subnet: "192.168.1.0", Cidr: 24, Asn: 351},
+				{Subnet: "192.168.0.0", Cidr: 20, Asn: 600},
+				{Subnet: "192.168.0.0", Cidr: 16, Asn: 355},
+			},
+		},
+		{
+			name: "Insert-Find IPv4 Address With Split Of Compressed Node",
+			ipCidrList: []ipCidrAsn{
+				{"10.1.2.0/24", 100},
+				{"10.1.3.0/24", 101},
+				{"10.0.0.0/8", 102},
+				{"10.1.2.128/25", 103},
+			},
+			ipToFind: "10.1.2.200/32",
+			want: NodeInfoList{
+				{Subnet: "10.1.2.128", Cidr: 25, Asn: 103},
+				{Subnet: "10.1.2.0", Cidr: 24, Asn: 100},
+				{Subnet: "10.0.0.0", Cidr: 8, Asn: 102},
+			},
+		},
+		{
+			name: "Insert-Find IPv4 Address Without Match Below Compressed Node",
+			ipCidrList: []ipCidrAsn{
+				{"10.1.2.0/24", 100},
+			},
+			ipToFind: "10.1.3.1/32",
+			want:     NodeInfoList{},
+		},
+		{
+			name: "Insert-Find IPv4 Default Route",
+			ipCidrList: []ipCidrAsn{
+				{"0.0.0.0/0", 100},
+				{"0.0.0.0/8", 101},
+				{"10.0.0.0/8", 102},
+			},
+			ipToFind: "0.1.2.3/32",
+			want: NodeInfoList{
+				{Subnet: "0.0.0.0", Cidr: 8, Asn: 101},
+				{Subnet: "0.0.0.0", Cidr: 0, Asn: 100},
+			},
+		},
+		{
+			name: "Insert-Find IPv6 Default Route",
+			ipCidrList: []ipCidrAsn{
+				{"::/0", 100},
+				{"0.0.0.0/0", 101},
+				{"2001:db8::/32", 102},
+			},
+			ipToFind: "2001:db8::1/128",
+			want: NodeInfoList{
+				{Subnet: "2001:db8::", Cidr: 32, Asn: 102},
+				{Subnet: "::", Cidr: 0, Asn: 100},
+			},
+		},
+		{
+			name: "Insert-Find IPv6 Address",
+			ipCidrList: []ipCidrAsn{
+				{"2001:db8:0:b::1A:1c/64", 451},
+				{"2604:a880:2:d0::2249:2001/77", 455},
+				{"2001:db8:0:b::1A:1c/67", 550},
+				{"fe80::c7e:afff:fe10:66e0/64", 700},
+				{"2001:db8:0:b::1A:1c/80", 300},
+			},
+			ipToFind: "2001:db8:0:b::1A:1c/128",
+			want: NodeInfoList{
+				{Subnet: "2001:db8:0:b::", Cidr: 80, Asn: 300},
+				{Subnet: "2001:db8:0:b::", Cidr: 67, Asn: 550},
+				{Subnet: "2001:db8:0:b::", Cidr: 64, Asn: 451},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		trie := NewRadixTrie()
+		for _, ipCidr := range testCase.ipCidrList {
+			ipAddress, err := newTestIPAddress(ipCidr.ip, ipCidr.asn)
+			if err != nil {
+				t.Fatalf("%s: received error for %s/%d does not match: got %v, want %v", testCase.name, ipCidr.ip, ipCidr.asn, err, nil)
+			}
+			Insert(trie, ipAddress)
+		}
+
+		ipToFind, err := newTestIPAddress(testCase.ipToFind, -1)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		got := Find(&Config{IPToFind: ipToFind, trie: trie})
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+// TestRadixTrieEquivalence inserts same random routes into Trie & RadixTrie
+// and checks that both return same NodeInfoList for random target addresses
+func TestRadixTrieEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	binaryTrie := NewTrie()
+	radixTrie := NewRadixTrie()
+
+	routes := append(randomIPv4Cidrs(r, 1000), randomIPv6Cidrs(r, 2000)...)
+	for i, route := range routes {
+		ipAddress, err := newTestIPAddress(route, i)
+		if err != nil {
+			t.Fatalf("received error for %s does not match: got %v, want %v", route, err, nil)
+		}
+
+		// Insert some routes twice to get more than one NodeInfo per node
+		Insert(binaryTrie, ipAddress)
+		Insert(radixTrie, ipAddress)
+		if i%10 == 0 {
+			Insert(binaryTrie, ipAddress)
+			Insert(radixTrie, ipAddress)
+		}
+	}
+
+	// Look up addresses inside inserted routes as well as random addresses
+	targets := append(routes, randomIPv4Cidrs(r, 1000)...)
+	targets = append(targets, randomIPv6Cidrs(r, 1000)...)
+
+	for _, target := range targets {
+		ipToFind, err := newIPToFind(hostAddress(target))
+		if err != nil {
+			t.Fatalf("received error for %s does not match: got %v, want %v", target, err, nil)
+		}
+
+		want := binaryTrie.Find(ipToFind)
+		got := radixTrie.Find(ipToFind)
+		if reflect.DeepEqual(got, want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", target, got, want)
+		}
+
+		// Longest match is first entry of Find() result
+		for _, trie := range []RouteTrie{binaryTrie, radixTrie} {
+			longest, ok := trie.LongestMatch(ipToFind)
+			if ok != (len(want) > 0) || ok && reflect.DeepEqual(longest, want[0]) != true {
+				t.Fatalf("%s: longest match does not match: got %v, want %v", target, longest, want)
+			}
+		}
+	}
+
+	// Look up covering and covered routes of a sample of prefixes. Short
+	// prefixes cover most of the trie, so all targets would take too long.
+	for i := 0; i < len(targets); i += 20 {
+		target := targets[i]
+		prefix, err := newTestIPAddress(target, -1)
+		if err != nil {
+			t.Fatalf("received error for %s does not match: got %v, want %v", target, err, nil)
+		}
+
+		want := binaryTrie.Find(prefix)
+		got := radixTrie.Find(prefix)
+		if reflect.DeepEqual(got, want) != true {
+			t.Fatalf("%s: covering routes do not match: got %v, want %v", target, got, want)
+		}
+
+		want = binaryTrie.FindCovered(prefix)
+		got = radixTrie.FindCovered(prefix)
+		if reflect.DeepEqual(got, want) != true {
+			t.Fatalf("%s: covered routes do not match: got %v, want %v", target, got, want)
+		}
+	}
+}
+
+func TestTrieDelete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		routes  []ipCidrAsn
+		deleted ipCidrAsn
+		removed bool
+		want    []ipCidrAsn
+	}{
+		{
+			name:    "Delete Leaf Below Covering Route",
+			routes:  []ipCidrAsn{{"8.0.0.0/8", 3356}, {"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"8.8.8.0/24", 15169},
+			removed: true,
+			want:    []ipCidrAsn{{"8.0.0.0/8", 3356}},
+		},
+		{
+			name:    "Delete Covering Route",
+			routes:  []ipCidrAsn{{"8.0.0.0/8", 3356}, {"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"8.0.0.0/8", 3356},
+			removed: true,
+			want:    []ipCidrAsn{{"8.8.8.0/24", 15169}},
+		},
+		{
+			name:    "Delete Sibling Route",
+			routes:  []ipCidrAsn{{"8.8.8.0/24", 15169}, {"8.8.4.0/24", 15169}, {"1.1.1.0/24", 13335}},
+			deleted: ipCidrAsn{"8.8.4.0/24", 15169},
+			removed: true,
+			want:    []ipCidrAsn{{"8.8.8.0/24", 15169}, {"1.1.1.0/24", 13335}},
+		},
+		{
+			name:    "Delete Route Between Branches",
+			routes:  []ipCidrAsn{{"8.8.0.0/16", 15169}, {"8.8.8.0/24", 15169}, {"8.8.4.0/24", 15169}},
+			deleted: ipCidrAsn{"8.8.0.0/16", 15169},
+			removed: true,
+			want:    []ipCidrAsn{{"8.8.8.0/24", 15169}, {"8.8.4.0/24", 15169}},
+		},
+		{
+			name:    "Delete One Origin",
+			routes:  []ipCidrAsn{{"8.8.8.0/24", 15169}, {"8.8.8.0/24", 350}},
+			deleted: ipCidrAsn{"8.8.8.0/24", 15169},
+			removed: true,
+			want:    []ipCidrAsn{{"8.8.8.0/24", 350}},
+		},
+		{
+			name:    "Delete Default Route",
+			routes:  []ipCidrAsn{{"0.0.0.0/0", 3356}, {"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"0.0.0.0/0", 3356},
+			removed: true,
+			want:    []ipCidrAsn{{"8.8.8.0/24", 15169}},
+		},
+		{
+			name:    "Delete IPv6 Route",
+			routes:  []ipCidrAsn{{"2604:a880::/32", 14061}, {"2604:a880:2:d0::/64", 14061}, {"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"2604:a880:2:d0::/64", 14061},
+			removed: true,
+			want:    []ipCidrAsn{{"2604:a880::/32", 14061}, {"8.8.8.0/24", 15169}},
+		},
+		{
+			name:    "Delete Last Route",
+			routes:  []ipCidrAsn{{"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"8.8.8.0/24", 15169},
+			removed: true,
+			want:    []ipCidrAsn{},
+		},
+		{
+			name:    "Delete Unknown Prefix",
+			routes:  []ipCidrAsn{{"8.0.0.0/8", 3356}, {"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"8.8.0.0/16", 15169},
+			removed: false,
+			want:    []ipCidrAsn{{"8.0.0.0/8", 3356}, {"8.8.8.0/24", 15169}},
+		},
+		{
+			name:    "Delete Unknown Origin",
+			routes:  []ipCidrAsn{{"8.8.8.0/24", 15169}},
+			deleted: ipCidrAsn{"8.8.8.0/24", 350},
+			removed: false,
+			want:    []ipCidrAsn{{"8.8.8.0/24", 15169}},
+		},
+	}
+
+	for _, newTrie := range []func() RouteTrie{newTestTrie, newTestRadixTrie} {
+		for _, testCase := range testCases {
+			trie := buildTestTrie(t, newTrie, testCase.routes)
+			want := buildTestTrie(t, newTrie, testCase.want)
+
+			ipAddress, err := newTestIPAddress(testCase.deleted.ip, -1)
+			if err != nil {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+			}
+
+			removed := trie.Delete(ipAddress, ASN(testCase.deleted.asn))
+			if (len(removed) > 0) != testCase.removed {
+				t.Fatalf("%s: removed does not match: got %v, want %v", testCase.name, removed, testCase.removed)
+			}
+
+			// Trie must be same as one built without deleted route
+			if reflect.DeepEqual(trie, want) != true {
+				t.Fatalf("%s: trie does not match trie built without %s", testCase.name, testCase.deleted.ip)
+			}
+		}
+	}
+}
+
+func TestTrieDeleteEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	routes := []ipCidrAsn{}
+	for i, route := range append(randomIPv4Cidrs(r, 2000), randomIPv6Cidrs(r, 2000)...) {
+		routes = append(routes, ipCidrAsn{route, i})
+	}
+
+	// Delete every third route and compare with trie built from the others
+	kept := []ipCidrAsn{}
+	for i, route := range routes {
+		if i%3 != 0 {
+			kept = append(kept, route)
+		}
+	}
+
+	for _, newTrie := range []func() RouteTrie{newTestTrie, newTestRadixTrie} {
+		trie := buildTestTrie(t, newTrie, routes)
+		for i, route := range routes {
+			if i%3 != 0 {
+				continue
+			}
+
+			ipAddress, err := newTestIPAddress(route.ip, -1)
+			if err != nil {
+				t.Fatalf("received error for %s does not match: got %v, want %v", route.ip, err, nil)
+			}
+
+			if len(trie.Delete(ipAddress, ASN(route.asn))) == 0 {
+				t.Fatalf("%s: route was not deleted", route.ip)
+			}
+		}
+
+		if want := buildTestTrie(t, newTrie, kept); reflect.DeepEqual(trie, want) != true {
+			t.Fatalf("trie does not match trie built without deleted routes")
+		}
+	}
+}
+
+func TestTrieUpdate(t *testing.T) {
+	for _, newTrie := range []func() RouteTrie{newTestTrie, newTestRadixTrie} {
+		trie := buildTestTrie(t, newTrie, []ipCidrAsn{{"8.8.8.0/24", 15169}, {"8.8.8.0/24", 350}, {"8.0.0.0/8", 3356}})
+
+		ipAddress, _ := newTestIPAddress("8.8.8.0/24", 13335)
+		replaced := trie.Update(ipAddress)
+		wantReplaced := NodeInfoList{
+			{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+			{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+		}
+		if reflect.DeepEqual(replaced, wantReplaced) != true {
+			t.Fatalf("replaced routes do not match: got %v, want %v", replaced, wantReplaced)
+		}
+
+		ipAddress, _ = newTestIPAddress("1.1.1.0/24", 13335)
+		if replaced = trie.Update(ipAddress); replaced != nil {
+			t.Fatalf("replaced routes do not match: got %v, want %v", replaced, nil)
+		}
+
+		want := buildTestTrie(t, newTrie, []ipCidrAsn{{"8.8.8.0/24", 13335}, {"8.0.0.0/8", 3356}, {"1.1.1.0/24", 13335}})
+		if reflect.DeepEqual(trie, want) != true {
+			t.Fatalf("trie does not match trie built with updated routes")
+		}
+	}
+}
+
+func TestLongestMatchAllocs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	routes := append(randomIPv4Cidrs(r, 1000), randomIPv6Cidrs(r, 1000)...)
+	ipToFind4, _ := newIPToFind(hostAddress(routes[0]))
+	ipToFind6, _ := newIPToFind(hostAddress(routes[len(routes)-1]))
+
+	for _, trie := range []RouteTrie{NewTrie(), NewRadixTrie()} {
+		for i, route := range routes {
+			ipAddress, _ := newTestIPAddress(route, i)
+			trie.Insert(ipAddress)
+		}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			trie.LongestMatch(ipToFind4)
+			trie.LongestMatch(ipToFind6)
+		})
+		if allocs != 0 {
+			t.Fatalf("%T: allocations do not match: got %v, want %v", trie, allocs, 0)
+		}
+	}
+}
+
+func BenchmarkTrieInsert(b *testing.B) {
+	benchmarkInsert(b, func() RouteTrie { return NewTrie() })
+}
+
+func BenchmarkRadixTrieInsert(b *testing.B) {
+	benchmarkInsert(b, func() RouteTrie { return NewRadixTrie() })
+}
+
+func BenchmarkTrieFind(b *testing.B) {
+	benchmarkFind(b, func() RouteTrie { return NewTrie() })
+}
+
+func BenchmarkRadixTrieFind(b *testing.B) {
+	benchmarkFind(b, func() RouteTrie { return NewRadixTrie() })
+}
+
+func BenchmarkTrieLongestMatch(b *testing.B) {
+	benchmarkLongestMatch(b, func() RouteTrie { return NewTrie() })
+}
+
+func BenchmarkRadixTrieLongestMatch(b *testing.B) {
+	benchmarkLongestMatch(b, func() RouteTrie { return NewRadixTrie() })
+}
+
+// benchmarkInsert measures memory and time to insert 10000 IPv6 routes
+func benchmarkInsert(b *testing.B, newTrie func() RouteTrie) {
+	r := rand.New(rand.NewSource(1))
+	ipAddressList := []IPAddress{}
+	for i, route := range randomIPv6Cidrs(r, 10000) {
+		ipAddress, _ := newIPv6Address(route, i)
+		ipAddressList = append(ipAddressList, ipAddress)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie := newTrie()
+		for _, ipAddress := range ipAddressList {
+			trie.Insert(ipAddress)
+		}
+	}
+}
+
+func benchmarkFind(b *testing.B, newTrie func() RouteTrie) {
+	r := rand.New(rand.NewSource(1))
+	trie := newTrie()
+	for i, route := range randomIPv4Cidrs(r, 10000) {
+		ipAddress, _ := newIPv4Address(route, i)
+		trie.Insert(ipAddress)
+	}
+	ipToFind, _ := newIPToFind("10.1.2.3")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.Find(ipToFind)
+	}
+}
+
+// benchmarkLongestMatch looks up same address as benchmarkFind, so
+// results compare LongestMatch against Find
+func benchmarkLongestMatch(b *testing.B, newTrie func() RouteTrie) {
+	r := rand.New(rand.NewSource(1))
+	trie := newTrie()
+	for i, route := range randomIPv4Cidrs(r, 10000) {
+		ipAddress, _ := newIPv4Address(route, i)
+		trie.Insert(ipAddress)
+	}
+	ipToFind, _ := newIPToFind("10.1.2.3")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.LongestMatch(ipToFind)
+	}
+}
+
+// newTestTrie and newTestRadixTrie create empty tries as RouteTrie
+func newTestTrie() RouteTrie      { return NewTrie() }
+func newTestRadixTrie() RouteTrie { return NewRadixTrie() }
+
+// buildTestTrie returns trie created by "newTrie" with "routes" inserted
+func buildTestTrie(t *testing.T, newTrie func() RouteTrie, routes []ipCidrAsn) RouteTrie {
+	trie := newTrie()
+	for _, route := range routes {
+		ipAddress, err := newTestIPAddress(route.ip, route.asn)
+		if err != nil {
+			t.Fatalf("received error for %s does not match: got %v, want %v", route.ip, err, nil)
+		}
+
+		trie.Insert(ipAddress)
+	}
+
+	return trie
+}
+
+// newTestIPAddress returns IPv4 or IPv6 address for "ipCidr"
+func newTestIPAddress(ipCidr string, asn int) (IPAddress, error) {
+	if isValidIPv4Cidr(ipCidr) {
+		return newIPv4Address(ipCidr, asn)
+	}
+
+	return newIPv6Address(ipCidr, asn)
+}
+
+// hostAddress returns address part of "ipCidr"
+func hostAddress(ipCidr string) string {
+	return strings.Split(ipCidr, "/")[0]
+}
+
+// randomIPv4Cidrs returns "n" random IPv4 CIDRs. Addresses are drawn from
+// a few /8 blocks so that routes overlap.
+func randomIPv4Cidrs(r *rand.Rand, n int) []string {
+	cidrs := []string{}
+	for i := 0; i < n; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("%d.%d.%d.%d/%d", 8+r.Intn(4), r.Intn(4), r.Intn(256), r.Intn(256), 8+r.Intn(25)))
+	}
+
+	return cidrs
+}
+
+// randomIPv6Cidrs returns "n" random IPv6 CIDRs. Addresses are drawn from
+// a few /16 blocks so that routes overlap.
+func randomIPv6Cidrs(r *rand.Rand, n int) []string {
+	cidrs := []string{}
+	for i := 0; i < n; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("%x:%x:%x:%x:%x::%x/%d", 0x2001+r.Intn(4), r.Intn(4), r.Intn(65536), r.Intn(65536), r.Intn(65536), r.Intn(65536), 1+r.Intn(128)))
+	}
+
+	return cidrs
+}
@@ -0,0 +1,396 @@
+package asnlookup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Output formats supported by NewResultWriter
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+var (
+	// ErrUnknownFormat is returned when output format is not supported
+	ErrUnknownFormat = errors.New("Unknown output format, use text, json, ndjson or csv")
+
+	// ErrUnknownASNotation is returned when ASN notation is not supported
+	ErrUnknownASNotation = errors.New("Unknown ASN notation, use asplain, asdot or asdot+")
+)
+
+// Result holds lookup result for one target IP address.
+// Matches are in NodeInfoList sort order, most specific first. Special is
+// set when query is in special purpose address block, e.g. private use
+// or documentation, whether or not any route matches. Translation is set
+// when IPv6 query was also looked up as embedded IPv4 address, see
+// WithIPv4Translation.
+type Result struct {
+	Query       string          `json:"query"`
+	Special     *SpecialPurpose `json:"special,omitempty"`
+	Translation *Translation    `json:"translation,omitempty"`
+	Matches     []Match         `json:"matches"`
+}
+
+// Match holds one matched NodeInfo entry of a Result. Longest is set
+// for entries with longest matching prefix length; when translated IPv4
+// address matched, only translated entries are longest. ASSet is set when
+// origin of the route is an AS_SET. Special is set when origin ASN or
+// prefix is special purpose, see NodeInfo.Classify(). Translated is set
+// for matches of IPv4 address embedded in IPv6 query.
+type Match struct {
+	Prefix     string          `json:"prefix"`
+	Subnet     string          `json:"subnet"`
+	Cidr       int             `json:"cidr"`
+	Asn        ASN             `json:"asn"`
+	ASSet      []ASN           `json:"as_set,omitempty"`
+	Longest    bool            `json:"longest"`
+	Special    *Classification `json:"special,omitempty"`
+	Translated bool            `json:"translated,omitempty"`
+}
+
+// NewResult creates Result for target IP address "query" from sorted
+// NodeInfoList returned by Find or Table.Lookup
+func NewResult(query string, nodeInfoList NodeInfoList) Result {
+	result := Result{
+		Query:   query,
+		Matches: make([]Match, 0, len(nodeInfoList)),
+	}
+
+	if ipToFind, err := newIPToFind(query); err == nil {
+		result.Special = classifyAddress(ipToFind)
+	}
+
+	for _, info := range nodeInfoList {
+		match := Match{
+			Prefix:  info.Prefix(),
+			Subnet:  info.Subnet,
+			Cidr:    info.Cidr,
+			Asn:     info.Asn,
+			ASSet:   info.ASSet,
+			Longest: info.Cidr == nodeInfoList[0].Cidr,
+		}
+		if c := info.Classify(); c.ASN != nil || c.Prefix != nil {
+			match.Special = &c
+		}
+		result.Matches = append(result.Matches, match)
+	}
+
+	return result
+}
+
+// Query looks up target IP address "addr" and returns it as Result. With
+// WithIPv4Translation, IPv4 address embedded in IPv4-mapped or NAT64
+// query is looked up too, and its matches come before IPv6 matches and
+// are the only ones marked as longest.
+func (t *Table) Query(addr string) (Result, error) {
+	ipToFind, err := newIPToFind(addr)
+	if err != nil {
+		return Result{}, err
+	}
+
+	data := t.rlock()
+	defer data.mu.RUnlock()
+	trie := data.trie
+	result := NewResult(addr, trie.Find(ipToFind))
+	if !t.translateIPv4 {
+		return result, nil
+	}
+
+	tr, ipv4 := translateIPv4(ipToFind)
+	if tr == nil {
+		return result, nil
+	}
+
+	translated := NewResult(tr.IPv4, trie.Find(ipv4))
+	for i := range translated.Matches {
+		translated.Matches[i].Translated = true
+	}
+	// Longest match of the whole result is match of the embedded address
+	if len(translated.Matches) > 0 {
+		for i := range result.Matches {
+			result.Matches[i].Longest = false
+		}
+	}
+
+	result.Translation = tr
+	result.Matches = append(translated.Matches, result.Matches...)
+
+	return result, nil
+}
+
+// ResultWriter writes lookup results in one output format.
+// Flush must be called after last Write.
+type ResultWriter interface {
+	Write(r Result) error
+	Flush() error
+}
+
+// WriterOption configures ResultWriter
+type WriterOption func(*writerOptions)
+
+type writerOptions struct {
+	notation   ASNotation
+	expandIPv6 bool
+}
+
+// WithASNotation prints ASNs in "notation" in text and CSV output. JSON
+// output always has ASNs as numbers. By default ASPlain is used.
+func WithASNotation(notation ASNotation) WriterOption {
+	return func(o *writerOptions) {
+		o.notation = notation
+	}
+}
+
+// WithExpandedIPv6 prints matched IPv6 prefixes in uncompressed format
+// (2001:0db8:0000:...) in all output formats. By default they are printed
+// in RFC 5952 canonical format (2001:db8::).
+func WithExpandedIPv6() WriterOption {
+	return func(o *writerOptions) {
+		o.expandIPv6 = true
+	}
+}
+
+// NewResultWriter returns ResultWriter writing "format" to "w".
+// For text format, query is printed on its own line before indented
+// matches of each result when "withQuery" is true. Other formats
+// always include the query.
+func NewResultWriter(w io.Writer, format string, withQuery bool, opts ...WriterOption) (ResultWriter, error) {
+	o := &writerOptions{
+		notation: ASPlain,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.notation != ASPlain && o.notation != ASDot && o.notation != ASDotPlus {
+		return nil, ErrUnknownASNotation
+	}
+
+	switch format {
+	case FormatText:
+		return &textWriter{w: w, withQuery: withQuery, o: o}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w, o: o}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w), o: o}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), o: o}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// result returns "r" with IPv6 prefixes of matches in format selected
+// by options
+func (o *writerOptions) result(r Result) Result {
+	if !o.expandIPv6 {
+		return r
+	}
+
+	matches := make([]Match, 0, len(r.Matches))
+	for _, m := range r.Matches {
+		m.Subnet = ExpandIPv6(m.Subnet)
+		m.Prefix = m.Subnet + "/" + strconv.Itoa(m.Cidr)
+		matches = append(matches, m)
+	}
+	r.Matches = matches
+
+	return r
+}
+
+// origin returns origin of the match as text, see NodeInfo.OriginNotation()
+func (m Match) origin(notation ASNotation) string {
+	return NodeInfo{Asn: m.Asn, ASSet: m.ASSet}.OriginNotation(notation)
+}
+
+// textWriter writes "<subnet>/<cidr> <origin>" line for each match.
+// Special purpose query, prefix or ASN is noted in a "#" comment.
+type textWriter struct {
+	w         io.Writer
+	withQuery bool
+	o         *writerOptions
+}
+
+func (t *textWriter) Write(r Result) error {
+	r = t.o.result(r)
+	indent := ""
+	if t.withQuery {
+		comment := ""
+		if notes := queryNotes(r); notes != "" {
+			comment = " # " + notes
+		}
+		if _, err := fmt.Fprintf(t.w, "%s%s\n", r.Query, comment); err != nil {
+			return err
+		}
+		indent = "\t"
+	} else if notes := queryNotes(r); notes != "" {
+		if _, err := fmt.Fprintf(t.w, "# %s: %s\n", r.Query, notes); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range r.Matches {
+		comment := ""
+		if m.Special != nil {
+			comment = specialComment(*m.Special)
+		}
+		if _, err := fmt.Fprintf(t.w, "%s%s %s%s\n", indent, m.Prefix, m.origin(t.o.notation), comment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryNotes returns special purpose and translation of query of "r" as
+// text, or empty string if it has neither
+func queryNotes(r Result) string {
+	notes := []string{}
+	if r.Special != nil {
+		notes = append(notes, "address "+r.Special.String())
+	}
+	if r.Translation != nil {
+		notes = append(notes, r.Translation.String())
+	}
+
+	return strings.Join(notes, ", ")
+}
+
+// specialComment returns " # prefix <purpose>, ASN <purpose>" comment
+// for special purpose parts of "c", or empty string if none is special
+func specialComment(c Classification) string {
+	if notes := specialNotes(c); notes != "" {
+		return " # " + notes
+	}
+	return ""
+}
+
+// specialNotes returns "prefix <purpose>, ASN <purpose>" for special
+// purpose parts of "c", or empty string if none is special
+func specialNotes(c Classification) string {
+	parts := []string{}
+	if c.Prefix != nil {
+		parts = append(parts, "prefix "+c.Prefix.String())
+	}
+	if c.ASN != nil {
+		parts = append(parts, "ASN "+c.ASN.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (t *textWriter) Flush() error {
+	return nil
+}
+
+// jsonWriter writes all results as one JSON array, one result per line
+type jsonWriter struct {
+	w     io.Writer
+	o     *writerOptions
+	count int
+}
+
+func (j *jsonWriter) Write(r Result) error {
+	b, err := json.Marshal(j.o.result(r))
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, b)
+	return err
+}
+
+func (j *jsonWriter) Flush() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// ndjsonWriter writes one JSON object per result per line
+type ndjsonWriter struct {
+	enc *json.Encoder
+	o   *writerOptions
+}
+
+func (n *ndjsonWriter) Write(r Result) error {
+	return n.enc.Encode(n.o.result(r))
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// csvWriter writes header followed by one row per match. Result without
+// matches is written as one row with empty match columns. Special column
+// notes special purpose prefix and ASN of the match, or special purpose
+// query address of row without match.
+type csvWriter struct {
+	w             *csv.Writer
+	o             *writerOptions
+	headerWritten bool
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+
+	c.headerWritten = true
+	return c.w.Write([]string{"query", "prefix", "asn", "longest", "special", "translated"})
+}
+
+func (c *csvWriter) Write(r Result) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	r = c.o.result(r)
+	if len(r.Matches) == 0 {
+		special := ""
+		if r.Special != nil {
+			special = "address " + r.Special.String()
+		}
+		return c.w.Write([]string{r.Query, "", "", "", special, ""})
+	}
+
+	for _, m := range r.Matches {
+		special := ""
+		if m.Special != nil {
+			special = specialNotes(*m.Special)
+		}
+
+		row := []string{r.Query, m.Prefix, m.origin(c.o.notation), strconv.FormatBool(m.Longest), special, strconv.FormatBool(m.Translated)}
+		if err := c.w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
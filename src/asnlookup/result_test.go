@@ -0,0 +1,311 @@
+package asnlookup
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNewResult(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		nodeInfoList NodeInfoList
+		want         Result
+	}{
+		{
+			name:  "Result With Longest Match Marked",
+			query: "8.8.8.8",
+			nodeInfoList: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 351},
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+			want: Result{
+				Query: "8.8.8.8",
+				Matches: []Match{
+					{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350, Longest: true},
+					{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 351, Longest: true},
+					{Prefix: "8.0.0.0/9", Subnet: "8.0.0.0", Cidr: 9, Asn: 352, Longest: false},
+				},
+			},
+		},
+		{
+			name:         "Result Without Matches",
+			query:        "1.1.1.1",
+			nodeInfoList: NodeInfoList{},
+			want: Result{
+				Query:   "1.1.1.1",
+				Matches: []Match{},
+			},
+		},
+		{
+			name:  "Result With Special Purpose Prefix And ASN",
+			query: "10.1.2.3",
+			nodeInfoList: NodeInfoList{
+				{Subnet: "10.0.0.0", Cidr: 8, Asn: 64512},
+			},
+			want: Result{
+				Query:   "10.1.2.3",
+				Special: &SpecialPurpose{"Private-Use", "RFC 1918"},
+				Matches: []Match{
+					{Prefix: "10.0.0.0/8", Subnet: "10.0.0.0", Cidr: 8, Asn: 64512, Longest: true, Special: &Classification{
+						ASN:    &SpecialPurpose{"Private-Use", "RFC 6996"},
+						Prefix: &SpecialPurpose{"Private-Use", "RFC 1918"},
+					}},
+				},
+			},
+		},
+		{
+			name:         "Special Purpose Result Without Matches",
+			query:        "2001:db8::1",
+			nodeInfoList: NodeInfoList{},
+			want: Result{
+				Query:   "2001:db8::1",
+				Special: &SpecialPurpose{"Documentation", "RFC 3849"},
+				Matches: []Match{},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := NewResult(testCase.query, testCase.nodeInfoList)
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestResultWriter(t *testing.T) {
+	results := []Result{
+		NewResult("8.8.8.8", NodeInfoList{
+			{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+			{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+		}),
+		NewResult("1.1.1.1", NodeInfoList{}),
+		NewResult("2.2.2.2", NodeInfoList{
+			{Subnet: "2.2.2.0", Cidr: 24, Asn: 196618},
+			{Subnet: "2.0.0.0", Cidr: 8, Asn: 100, ASSet: []ASN{100, 196618}},
+		}),
+		NewResult("10.1.2.3", NodeInfoList{
+			{Subnet: "10.0.0.0", Cidr: 8, Asn: 64512},
+		}),
+		NewResult("192.0.2.1", NodeInfoList{}),
+		NewResult("2604:a880:2:d0::1", NodeInfoList{
+			{Subnet: "2604:a880:2:d0::", Cidr: 64, Asn: 440},
+		}),
+	}
+
+	testCases := []struct {
+		name      string
+		format    string
+		withQuery bool
+		opts      []WriterOption
+		results   []Result
+		want      string
+		err       error
+	}{
+		{
+			name:    "Write Text",
+			format:  FormatText,
+			results: results[:1],
+			want:    "8.8.8.0/24 350\n8.0.0.0/9 352\n",
+			err:     nil,
+		},
+		{
+			name:    "Write Text In Asdot Notation",
+			format:  FormatText,
+			opts:    []WriterOption{WithASNotation(ASDot)},
+			results: results[2:3],
+			want:    "2.2.2.0/24 3.10\n2.0.0.0/8 {100,3.10}\n",
+			err:     nil,
+		},
+		{
+			name:    "Write CSV In Asdot+ Notation",
+			format:  FormatCSV,
+			opts:    []WriterOption{WithASNotation(ASDotPlus)},
+			results: results[2:3],
+			want: "query,prefix,asn,longest,special,translated\n" +
+				"2.2.2.2,2.2.2.0/24,3.10,true,,false\n" +
+				"2.2.2.2,2.0.0.0/8,\"{0.100,3.10}\",false,,false\n",
+			err: nil,
+		},
+		{
+			name:    "Write Unknown Notation",
+			format:  FormatText,
+			opts:    []WriterOption{WithASNotation("asdotdot")},
+			results: results[:2],
+			want:    "",
+			err:     ErrUnknownASNotation,
+		},
+		{
+			name:      "Write Text With Query",
+			format:    FormatText,
+			withQuery: true,
+			results:   results[:2],
+			want:      "8.8.8.8\n\t8.8.8.0/24 350\n\t8.0.0.0/9 352\n1.1.1.1\n",
+			err:       nil,
+		},
+		{
+			name:    "Write Text With Special Purpose",
+			format:  FormatText,
+			results: results[3:5],
+			want: "# 10.1.2.3: address Private-Use (RFC 1918)\n" +
+				"10.0.0.0/8 64512 # prefix Private-Use (RFC 1918), ASN Private-Use (RFC 6996)\n" +
+				"# 192.0.2.1: address Documentation (TEST-NET-1) (RFC 5737)\n",
+			err: nil,
+		},
+		{
+			name:      "Write Text With Query And Special Purpose",
+			format:    FormatText,
+			withQuery: true,
+			results:   results[3:5],
+			want: "10.1.2.3 # address Private-Use (RFC 1918)\n" +
+				"\t10.0.0.0/8 64512 # prefix Private-Use (RFC 1918), ASN Private-Use (RFC 6996)\n" +
+				"192.0.2.1 # address Documentation (TEST-NET-1) (RFC 5737)\n",
+			err: nil,
+		},
+		{
+			name:      "Write Text With Translation",
+			format:    FormatText,
+			withQuery: true,
+			results: []Result{{
+				Query:       "64:ff9b::808:808",
+				Translation: &Translation{Kind: TranslationNAT64, IPv4: "8.8.8.8"},
+				Matches:     []Match{{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350, Longest: true, Translated: true}},
+			}},
+			want: "64:ff9b::808:808 # translated to 8.8.8.8 (NAT64)\n" +
+				"\t8.8.8.0/24 350\n",
+			err: nil,
+		},
+		{
+			name:    "Write NDJSON With Special Purpose",
+			format:  FormatNDJSON,
+			results: results[3:5],
+			want: `{"query":"10.1.2.3","special":{"name":"Private-Use","rfc":"RFC 1918"},"matches":[` +
+				`{"prefix":"10.0.0.0/8","subnet":"10.0.0.0","cidr":8,"asn":64512,"longest":true,` +
+				`"special":{"asn":{"name":"Private-Use","rfc":"RFC 6996"},"prefix":{"name":"Private-Use","rfc":"RFC 1918"}}}]}` + "\n" +
+				`{"query":"192.0.2.1","special":{"name":"Documentation (TEST-NET-1)","rfc":"RFC 5737"},"matches":[]}` + "\n",
+			err: nil,
+		},
+		{
+			name:    "Write Text With IPv6",
+			format:  FormatText,
+			results: results[5:],
+			want:    "2604:a880:2:d0::/64 440\n",
+			err:     nil,
+		},
+		{
+			name:    "Write Text With Expanded IPv6",
+			format:  FormatText,
+			opts:    []WriterOption{WithExpandedIPv6()},
+			results: results[5:],
+			want:    "2604:a880:0002:00d0:0000:0000:0000:0000/64 440\n",
+			err:     nil,
+		},
+		{
+			name:    "Write NDJSON With Expanded IPv6",
+			format:  FormatNDJSON,
+			opts:    []WriterOption{WithExpandedIPv6()},
+			results: append(results[:1:1], results[5:]...),
+			want: `{"query":"8.8.8.8","matches":[` +
+				`{"prefix":"8.8.8.0/24","subnet":"8.8.8.0","cidr":24,"asn":350,"longest":true},` +
+				`{"prefix":"8.0.0.0/9","subnet":"8.0.0.0","cidr":9,"asn":352,"longest":false}]}` + "\n" +
+				`{"query":"2604:a880:2:d0::1","matches":[` +
+				`{"prefix":"2604:a880:0002:00d0:0000:0000:0000:0000/64","subnet":"2604:a880:0002:00d0:0000:0000:0000:0000","cidr":64,"asn":440,"longest":true}]}` + "\n",
+			err: nil,
+		},
+		{
+			name:    "Write JSON",
+			format:  FormatJSON,
+			results: results[:2],
+			want: "[\n" +
+				`{"query":"8.8.8.8","matches":[` +
+				`{"prefix":"8.8.8.0/24","subnet":"8.8.8.0","cidr":24,"asn":350,"longest":true},` +
+				`{"prefix":"8.0.0.0/9","subnet":"8.0.0.0","cidr":9,"asn":352,"longest":false}]},` + "\n" +
+				`{"query":"1.1.1.1","matches":[]}` + "\n]\n",
+			err: nil,
+		},
+		{
+			name:    "Write JSON Without Results",
+			format:  FormatJSON,
+			results: nil,
+			want:    "[]\n",
+			err:     nil,
+		},
+		{
+			name:    "Write NDJSON",
+			format:  FormatNDJSON,
+			results: results[:2],
+			want: `{"query":"8.8.8.8","matches":[` +
+				`{"prefix":"8.8.8.0/24","subnet":"8.8.8.0","cidr":24,"asn":350,"longest":true},` +
+				`{"prefix":"8.0.0.0/9","subnet":"8.0.0.0","cidr":9,"asn":352,"longest":false}]}` + "\n" +
+				`{"query":"1.1.1.1","matches":[]}` + "\n",
+			err: nil,
+		},
+		{
+			name:    "Write CSV",
+			format:  FormatCSV,
+			results: results[:2],
+			want: "query,prefix,asn,longest,special,translated\n" +
+				"8.8.8.8,8.8.8.0/24,350,true,,false\n" +
+				"8.8.8.8,8.0.0.0/9,352,false,,false\n" +
+				"1.1.1.1,,,,,\n",
+			err: nil,
+		},
+		{
+			name:    "Write CSV With Special Purpose",
+			format:  FormatCSV,
+			results: results[3:5],
+			want: "query,prefix,asn,longest,special,translated\n" +
+				"10.1.2.3,10.0.0.0/8,64512,true,\"prefix Private-Use (RFC 1918), ASN Private-Use (RFC 6996)\",false\n" +
+				"192.0.2.1,,,,address Documentation (TEST-NET-1) (RFC 5737),\n",
+			err: nil,
+		},
+		{
+			name:   "Write CSV With Translation",
+			format: FormatCSV,
+			results: []Result{{
+				Query:       "64:ff9b::808:808",
+				Translation: &Translation{Kind: TranslationNAT64, IPv4: "8.8.8.8"},
+				Matches:     []Match{{Prefix: "8.8.8.0/24", Subnet: "8.8.8.0", Cidr: 24, Asn: 350, Longest: true, Translated: true}},
+			}},
+			want: "query,prefix,asn,longest,special,translated\n" +
+				"64:ff9b::808:808,8.8.8.0/24,350,true,,true\n",
+			err: nil,
+		},
+		{
+			name:    "Write Unknown Format",
+			format:  "xml",
+			results: results[:2],
+			want:    "",
+			err:     ErrUnknownFormat,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		w, err := NewResultWriter(&buf, testCase.format, testCase.withQuery, testCase.opts...)
+		if err != testCase.err {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		for _, r := range testCase.results {
+			if err := w.Write(r); err != nil {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		if buf.String() != testCase.want {
+			t.Fatalf("%s: result does not match: got %q, want %q", testCase.name, buf.String(), testCase.want)
+		}
+	}
+}
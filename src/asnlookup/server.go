@@ -0,0 +1,188 @@
+package asnlookup
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxBatchSize is maximum number of IP addresses in one batch lookup
+	maxBatchSize = 10000
+
+	// maxBatchBodySize is maximum size of batch lookup request body in bytes
+	maxBatchBodySize = 1 << 20
+)
+
+// Server serves lookups from Table over HTTP as JSON. It serves
+//
+//	GET  /v1/lookup/{ip}  lookup of one IP address
+//	POST /v1/lookup       lookup of JSON array of IP addresses
+//	GET  /healthz         liveness, always OK
+//	GET  /readyz          readiness, OK once Table is set, with table age,
+//	                      outcome of last reload and skipped table lines
+//
+// Server is safe for concurrent use. Lookups return 503 until SetTable
+// is called.
+type Server struct {
+	mu    sync.RWMutex
+	table *Table
+	mux   *http.ServeMux
+}
+
+// batchResult is Result of one IP address in batch lookup. Error is set
+// instead of matches when address is invalid.
+type batchResult struct {
+	Result
+	Error string `json:"error,omitempty"`
+}
+
+// errorResponse is response body of failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// readyResponse is response body of /readyz once table is loaded. It
+// reports table age and outcome of last reload.
+type readyResponse struct {
+	Status          string         `json:"status"`
+	Routes          int            `json:"routes"`
+	LoadedAt        string         `json:"loaded_at"`
+	AgeSeconds      int64          `json:"age_seconds"`
+	LastReload      string         `json:"last_reload,omitempty"`
+	LastReloadError string         `json:"last_reload_error,omitempty"`
+	SkippedLines    map[string]int `json:"skipped_lines,omitempty"`
+	ASSetRoutes     int            `json:"as_set_routes,omitempty"`
+}
+
+// NewServer returns Server without Table. Table is set with SetTable
+// once it is loaded.
+func NewServer() *Server {
+	s := &Server{
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/v1/lookup/", s.handleLookup)
+	s.mux.HandleFunc("/v1/lookup", s.handleBatchLookup)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
+	return s
+}
+
+// SetTable sets Table used for lookups and marks Server ready
+func (s *Server) SetTable(t *Table) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.table = t
+}
+
+// getTable returns current Table or nil if it is not set yet
+func (s *Server) getTable() *Table {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.table
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"Method not allowed"})
+		return
+	}
+
+	table := s.getTable()
+	if table == nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{"Table is not loaded yet"})
+		return
+	}
+
+	result, err := table.Query(strings.TrimPrefix(r.URL.Path, "/v1/lookup/"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleBatchLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"Method not allowed"})
+		return
+	}
+
+	table := s.getTable()
+	if table == nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{"Table is not loaded yet"})
+		return
+	}
+
+	var ips []string
+	body := http.MaxBytesReader(w, r.Body, maxBatchBodySize)
+	if err := json.NewDecoder(body).Decode(&ips); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"Request body must be JSON array of IP addresses"})
+		return
+	}
+
+	if len(ips) > maxBatchSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{"Too many IP addresses in request"})
+		return
+	}
+
+	results := make([]batchResult, 0, len(ips))
+	for _, ip := range ips {
+		result, err := table.Query(ip)
+		if err != nil {
+			results = append(results, batchResult{Result: Result{Query: ip, Matches: []Match{}}, Error: err.Error()})
+			continue
+		}
+
+		results = append(results, batchResult{Result: result})
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	table := s.getTable()
+	if table == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "loading"})
+		return
+	}
+
+	status := table.Status()
+	resp := readyResponse{
+		Status:       "ready",
+		Routes:       status.Routes,
+		LoadedAt:     status.LoadedAt.UTC().Format(time.RFC3339),
+		AgeSeconds:   int64(status.Age().Seconds()),
+		SkippedLines: status.Skipped,
+		ASSetRoutes:  status.ASSetRoutes,
+	}
+
+	if !status.LastReload.IsZero() {
+		resp.LastReload = status.LastReload.UTC().Format(time.RFC3339)
+	}
+	if status.LastReloadError != nil {
+		resp.LastReloadError = status.LastReloadError.Error()
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeJSON writes "v" as JSON response with "status" code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
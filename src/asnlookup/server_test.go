@@ -0,0 +1,158 @@
+package asnlookup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		setTable   bool
+		wantStatus int
+		wantBody   string
+		wantPrefix bool
+	}{
+		{
+			name:       "Healthz Before Table Is Loaded",
+			method:     http.MethodGet,
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+		},
+		{
+			name:       "Readyz Before Table Is Loaded",
+			method:     http.MethodGet,
+			path:       "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"loading"}`,
+		},
+		{
+			name:       "Lookup Before Table Is Loaded",
+			method:     http.MethodGet,
+			path:       "/v1/lookup/8.8.8.8",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"error":"Table is not loaded yet"}`,
+		},
+		{
+			name:       "Readyz After Table Is Loaded",
+			method:     http.MethodGet,
+			path:       "/readyz",
+			setTable:   true,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ready","routes":6,"loaded_at":`,
+			wantPrefix: true,
+		},
+		{
+			name:       "Lookup IPv4 Address",
+			method:     http.MethodGet,
+			path:       "/v1/lookup/8.8.8.8",
+			setTable:   true,
+			wantStatus: http.StatusOK,
+			wantBody: `{"query":"8.8.8.8","matches":[` +
+				`{"prefix":"8.8.8.0/24","subnet":"8.8.8.0","cidr":24,"asn":350,"longest":true},` +
+				`{"prefix":"8.0.0.0/12","subnet":"8.0.0.0","cidr":12,"asn":351,"longest":false},` +
+				`{"prefix":"8.0.0.0/9","subnet":"8.0.0.0","cidr":9,"asn":352,"longest":false}]}`,
+		},
+		{
+			name:       "Lookup Invalid Address",
+			method:     http.MethodGet,
+			path:       "/v1/lookup/8.8.8",
+			setTable:   true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid IP address in input"}`,
+		},
+		{
+			name:       "Lookup With Wrong Method",
+			method:     http.MethodPost,
+			path:       "/v1/lookup/8.8.8.8",
+			setTable:   true,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"Method not allowed"}`,
+		},
+		{
+			name:       "Batch Lookup",
+			method:     http.MethodPost,
+			path:       "/v1/lookup",
+			body:       `["192.121.43.1", "bad", "2604:a880:2:d0::1"]`,
+			setTable:   true,
+			wantStatus: http.StatusOK,
+			wantBody: `[{"query":"192.121.43.1","matches":[` +
+				`{"prefix":"192.121.43.0/24","subnet":"192.121.43.0","cidr":24,"asn":156,"longest":true}]},` +
+				`{"query":"bad","matches":[],"error":"Invalid IP address in input"},` +
+				`{"query":"2604:a880:2:d0::1","matches":[` +
+				`{"prefix":"2604:a880:2:d0::/65","subnet":"2604:a880:2:d0::","cidr":65,"asn":444,"longest":true},` +
+				`{"prefix":"2604:a880:2:d0::/64","subnet":"2604:a880:2:d0::","cidr":64,"asn":440,"longest":false}]}]`,
+		},
+		{
+			name:       "Batch Lookup With Invalid Body",
+			method:     http.MethodPost,
+			path:       "/v1/lookup",
+			body:       `{"ip": "8.8.8.8"}`,
+			setTable:   true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Request body must be JSON array of IP addresses"}`,
+		},
+	}
+
+	table, err := LoadTable("./config_file_test.txt")
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	for _, testCase := range testCases {
+		s := NewServer()
+		if testCase.setTable {
+			s.SetTable(table)
+		}
+
+		req := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body))
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.wantStatus {
+			t.Fatalf("%s: received status does not match: got %v, want %v", testCase.name, rec.Code, testCase.wantStatus)
+		}
+
+		got := strings.TrimSpace(rec.Body.String())
+		if testCase.wantPrefix && strings.HasPrefix(got, testCase.wantBody) {
+			continue
+		}
+		if got != testCase.wantBody {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.wantBody)
+		}
+	}
+}
+
+func TestServerConcurrentLookup(t *testing.T) {
+	table, err := LoadTable("./config_file_test.txt")
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	s := NewServer()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i == 25 {
+				s.SetTable(table)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/lookup/8.8.8.8", nil)
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK && rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("received status does not match: got %v, want %v or %v", rec.Code, http.StatusOK, http.StatusServiceUnavailable)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
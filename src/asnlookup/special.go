@@ -0,0 +1,172 @@
+package asnlookup
+
+import "fmt"
+
+// SpecialPurpose describes special purpose ASN range or address block,
+// e.g. private use ASNs or documentation prefixes. RFC is "IANA" for
+// ranges reserved by IANA without an RFC.
+type SpecialPurpose struct {
+	Name string `json:"name"`
+	RFC  string `json:"rfc"`
+}
+
+func (s SpecialPurpose) String() string {
+	return fmt.Sprintf("%s (%s)", s.Name, s.RFC)
+}
+
+// Classification holds special purpose of origin ASN and prefix of a
+// route. Field is nil when ASN or prefix is not special.
+type Classification struct {
+	ASN    *SpecialPurpose `json:"asn,omitempty"`
+	Prefix *SpecialPurpose `json:"prefix,omitempty"`
+}
+
+// specialASNRange is range of ASNs from First to Last inclusive
+type specialASNRange struct {
+	First   ASN
+	Last    ASN
+	Purpose SpecialPurpose
+}
+
+// specialASNs is IANA Special-Purpose AS Numbers registry, plus range
+// 65552-131071 which IANA keeps reserved in 32-bit AS Numbers registry
+var specialASNs = []specialASNRange{
+	{0, 0, SpecialPurpose{"Reserved", "RFC 7607"}},
+	{112, 112, SpecialPurpose{"AS112", "RFC 7534"}},
+	{23456, 23456, SpecialPurpose{"AS_TRANS", "RFC 6793"}},
+	{64496, 64511, SpecialPurpose{"Documentation", "RFC 5398"}},
+	{64512, 65534, SpecialPurpose{"Private-Use", "RFC 6996"}},
+	{65535, 65535, SpecialPurpose{"Reserved", "RFC 7300"}},
+	{65536, 65551, SpecialPurpose{"Documentation", "RFC 5398"}},
+	{65552, 131071, SpecialPurpose{"Reserved", "IANA"}},
+	{4200000000, 4294967294, SpecialPurpose{"Private-Use", "RFC 6996"}},
+	{4294967295, 4294967295, SpecialPurpose{"Reserved", "RFC 7300"}},
+}
+
+// specialBlock is special purpose address block
+type specialBlock struct {
+	block   IPAddress
+	purpose SpecialPurpose
+}
+
+// specialBlocks is IANA IPv4 and IPv6 Special-Purpose Address Registries
+// (RFC 6890). Registries change as new blocks are assigned, so this copy
+// may lag behind them.
+var specialBlocks = newSpecialBlocks(map[string]SpecialPurpose{
+	"0.0.0.0/8":          {"This Network", "RFC 791"},
+	"0.0.0.0/32":         {"This Host On This Network", "RFC 1122"},
+	"10.0.0.0/8":         {"Private-Use", "RFC 1918"},
+	"100.64.0.0/10":      {"Shared Address Space", "RFC 6598"},
+	"127.0.0.0/8":        {"Loopback", "RFC 1122"},
+	"169.254.0.0/16":     {"Link Local", "RFC 3927"},
+	"172.16.0.0/12":      {"Private-Use", "RFC 1918"},
+	"192.0.0.0/24":       {"IETF Protocol Assignments", "RFC 6890"},
+	"192.0.0.0/29":       {"IPv4 Service Continuity Prefix", "RFC 7335"},
+	"192.0.0.8/32":       {"IPv4 Dummy Address", "RFC 7600"},
+	"192.0.0.9/32":       {"Port Control Protocol Anycast", "RFC 7723"},
+	"192.0.0.10/32":      {"Traversal Using Relays around NAT Anycast", "RFC 8155"},
+	"192.0.0.170/32":     {"NAT64/DNS64 Discovery", "RFC 7050"},
+	"192.0.0.171/32":     {"NAT64/DNS64 Discovery", "RFC 7050"},
+	"192.0.2.0/24":       {"Documentation (TEST-NET-1)", "RFC 5737"},
+	"192.31.196.0/24":    {"AS112-v4", "RFC 7535"},
+	"192.52.193.0/24":    {"AMT", "RFC 7450"},
+	"192.88.99.0/24":     {"6to4 Relay Anycast", "RFC 7526"},
+	"192.168.0.0/16":     {"Private-Use", "RFC 1918"},
+	"192.175.48.0/24":    {"Direct Delegation AS112 Service", "RFC 7534"},
+	"198.18.0.0/15":      {"Benchmarking", "RFC 2544"},
+	"198.51.100.0/24":    {"Documentation (TEST-NET-2)", "RFC 5737"},
+	"203.0.113.0/24":     {"Documentation (TEST-NET-3)", "RFC 5737"},
+	"240.0.0.0/4":        {"Reserved", "RFC 1112"},
+	"255.255.255.255/32": {"Limited Broadcast", "RFC 919"},
+	"::/128":             {"Unspecified Address", "RFC 4291"},
+	"::1/128":            {"Loopback Address", "RFC 4291"},
+	"::ffff:0:0/96":      {"IPv4-mapped Address", "RFC 4291"},
+	"64:ff9b::/96":       {"IPv4-IPv6 Translation", "RFC 6052"},
+	"64:ff9b:1::/48":     {"IPv4-IPv6 Translation", "RFC 8215"},
+	"100::/64":           {"Discard-Only Address Block", "RFC 6666"},
+	"2001::/23":          {"IETF Protocol Assignments", "RFC 2928"},
+	"2001::/32":          {"TEREDO", "RFC 4380"},
+	"2001:1::1/128":      {"Port Control Protocol Anycast", "RFC 7723"},
+	"2001:1::2/128":      {"Traversal Using Relays around NAT Anycast", "RFC 8155"},
+	"2001:2::/48":        {"Benchmarking", "RFC 5180"},
+	"2001:3::/32":        {"AMT", "RFC 7450"},
+	"2001:4:112::/48":    {"AS112-v6", "RFC 7535"},
+	"2001:10::/28":       {"Deprecated (previously ORCHID)", "RFC 4843"},
+	"2001:20::/28":       {"ORCHIDv2", "RFC 7343"},
+	"2001:30::/28":       {"Drone Remote ID Protocol Entity Tags (DETs) Prefix", "RFC 9374"},
+	"2001:db8::/32":      {"Documentation", "RFC 3849"},
+	"2002::/16":          {"6to4", "RFC 3056"},
+	"2620:4f:8000::/48":  {"Direct Delegation AS112 Service", "RFC 7534"},
+	"3fff::/20":          {"Documentation", "RFC 9637"},
+	"5f00::/16":          {"Segment Routing (SRv6) SIDs", "RFC 9602"},
+	"fc00::/7":           {"Unique-Local", "RFC 4193"},
+	"fe80::/10":          {"Link-Local Unicast", "RFC 4291"},
+})
+
+// newSpecialBlocks parses address blocks of registry
+func newSpecialBlocks(registry map[string]SpecialPurpose) []specialBlock {
+	blocks := []specialBlock{}
+	for ipCidr, purpose := range registry {
+		block, err := newRouteAddress(ipCidr, 0)
+		if err != nil {
+			panic(fmt.Sprintf("invalid special purpose block %s: %v", ipCidr, err))
+		}
+		blocks = append(blocks, specialBlock{block: block, purpose: purpose})
+	}
+
+	return blocks
+}
+
+// ClassifyASN returns special purpose of "asn", or nil if it is not
+// reserved, private use or documentation ASN
+func ClassifyASN(asn ASN) *SpecialPurpose {
+	for _, r := range specialASNs {
+		if asn >= r.First && asn <= r.Last {
+			purpose := r.Purpose
+			return &purpose
+		}
+	}
+
+	return nil
+}
+
+// classifyAddress returns special purpose of most specific special
+// purpose block which contains "ip", or nil if there is none
+func classifyAddress(ip IPAddress) *SpecialPurpose {
+	var found *specialBlock
+	for i, b := range specialBlocks {
+		if covers(b.block, ip) && (found == nil || b.block.GetCidrLen() > found.block.GetCidrLen()) {
+			found = &specialBlocks[i]
+		}
+	}
+
+	if found == nil {
+		return nil
+	}
+
+	purpose := found.purpose
+	return &purpose
+}
+
+// Classify returns special purpose of origin ASN and prefix of the route.
+// For AS_SET origin, first special member of the set is used.
+func (n NodeInfo) Classify() Classification {
+	c := Classification{}
+
+	asns := n.ASSet
+	if len(asns) == 0 {
+		asns = []ASN{n.Asn}
+	}
+	for _, asn := range asns {
+		if c.ASN = ClassifyASN(asn); c.ASN != nil {
+			break
+		}
+	}
+
+	prefix, err := newRouteAddress(n.Prefix(), 0)
+	if err == nil {
+		c.Prefix = classifyAddress(prefix)
+	}
+
+	return c
+}
@@ -0,0 +1,167 @@
+package asnlookup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyASN(t *testing.T) {
+	testCases := []struct {
+		name string
+		asn  ASN
+		want *SpecialPurpose
+	}{
+		{
+			name: "Classify Public ASN",
+			asn:  13335,
+			want: nil,
+		},
+		{
+			name: "Classify Reserved ASN 0",
+			asn:  0,
+			want: &SpecialPurpose{"Reserved", "RFC 7607"},
+		},
+		{
+			name: "Classify AS112",
+			asn:  112,
+			want: &SpecialPurpose{"AS112", "RFC 7534"},
+		},
+		{
+			name: "Classify AS_TRANS",
+			asn:  23456,
+			want: &SpecialPurpose{"AS_TRANS", "RFC 6793"},
+		},
+		{
+			name: "Classify 16 Bit Documentation ASN",
+			asn:  64511,
+			want: &SpecialPurpose{"Documentation", "RFC 5398"},
+		},
+		{
+			name: "Classify 16 Bit Private Use ASN",
+			asn:  64512,
+			want: &SpecialPurpose{"Private-Use", "RFC 6996"},
+		},
+		{
+			name: "Classify Last 16 Bit ASN",
+			asn:  65535,
+			want: &SpecialPurpose{"Reserved", "RFC 7300"},
+		},
+		{
+			name: "Classify 32 Bit Documentation ASN",
+			asn:  65551,
+			want: &SpecialPurpose{"Documentation", "RFC 5398"},
+		},
+		{
+			name: "Classify Reserved 32 Bit ASN",
+			asn:  131071,
+			want: &SpecialPurpose{"Reserved", "IANA"},
+		},
+		{
+			name: "Classify Public 32 Bit ASN",
+			asn:  131072,
+			want: nil,
+		},
+		{
+			name: "Classify 32 Bit Private Use ASN",
+			asn:  4200000000,
+			want: &SpecialPurpose{"Private-Use", "RFC 6996"},
+		},
+		{
+			name: "Classify Last 32 Bit ASN",
+			asn:  4294967295,
+			want: &SpecialPurpose{"Reserved", "RFC 7300"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := ClassifyASN(testCase.asn)
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name string
+		info NodeInfo
+		want Classification
+	}{
+		{
+			name: "Classify Public Route",
+			info: NodeInfo{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169},
+			want: Classification{},
+		},
+		{
+			name: "Classify Private Use IPv4 Prefix",
+			info: NodeInfo{Subnet: "192.168.1.0", Cidr: 24, Asn: 15169},
+			want: Classification{Prefix: &SpecialPurpose{"Private-Use", "RFC 1918"}},
+		},
+		{
+			name: "Classify This Network Prefix",
+			info: NodeInfo{Subnet: "0.0.0.0", Cidr: 8, Asn: 15169},
+			want: Classification{Prefix: &SpecialPurpose{"This Network", "RFC 791"}},
+		},
+		{
+			name: "Classify This Host Address",
+			info: NodeInfo{Subnet: "0.0.0.0", Cidr: 32, Asn: 15169},
+			want: Classification{Prefix: &SpecialPurpose{"This Host On This Network", "RFC 1122"}},
+		},
+		{
+			name: "Classify AS112 IPv4 Prefix",
+			info: NodeInfo{Subnet: "192.175.48.0", Cidr: 24, Asn: 112},
+			want: Classification{
+				ASN:    &SpecialPurpose{"AS112", "RFC 7534"},
+				Prefix: &SpecialPurpose{"Direct Delegation AS112 Service", "RFC 7534"},
+			},
+		},
+		{
+			name: "Classify AS112 IPv6 Prefix",
+			info: NodeInfo{Subnet: "2620:4f:8000::", Cidr: 48, Asn: 112},
+			want: Classification{
+				ASN:    &SpecialPurpose{"AS112", "RFC 7534"},
+				Prefix: &SpecialPurpose{"Direct Delegation AS112 Service", "RFC 7534"},
+			},
+		},
+		{
+			name: "Classify ORCHIDv2 Prefix",
+			info: NodeInfo{Subnet: "2001:20::", Cidr: 28, Asn: 15169},
+			want: Classification{Prefix: &SpecialPurpose{"ORCHIDv2", "RFC 7343"}},
+		},
+		{
+			name: "Classify Default Route",
+			info: NodeInfo{Subnet: "0.0.0.0", Cidr: 0, Asn: 15169},
+			want: Classification{},
+		},
+		{
+			name: "Classify Prefix Covering Special Purpose Block",
+			info: NodeInfo{Subnet: "192.0.0.0", Cidr: 16, Asn: 15169},
+			want: Classification{},
+		},
+		{
+			name: "Classify Most Specific IPv6 Block",
+			info: NodeInfo{Subnet: "2001::", Cidr: 32, Asn: 15169},
+			want: Classification{Prefix: &SpecialPurpose{"TEREDO", "RFC 4380"}},
+		},
+		{
+			name: "Classify IPv6 Documentation Prefix",
+			info: NodeInfo{Subnet: "2001:db8:1::", Cidr: 48, Asn: 64496},
+			want: Classification{
+				ASN:    &SpecialPurpose{"Documentation", "RFC 5398"},
+				Prefix: &SpecialPurpose{"Documentation", "RFC 3849"},
+			},
+		},
+		{
+			name: "Classify AS_SET With Private Use Member",
+			info: NodeInfo{Subnet: "8.8.8.0", Cidr: 24, Asn: 15169, ASSet: []ASN{15169, 65000}},
+			want: Classification{ASN: &SpecialPurpose{"Private-Use", "RFC 6996"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := testCase.info.Classify()
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
@@ -0,0 +1,560 @@
+package asnlookup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTableURL is the URL routing table is fetched from when no
+// table file is given
+const DefaultTableURL = "http://lg01.infra.ring.nlnog.net/table.txt"
+
+// maxTableLineLen is maximum length of routing table line in bytes
+const maxTableLineLen = 1 << 20
+
+var (
+	// ErrNoTableSource is returned by Reload when Table was built from a
+	// reader and has no file or URL to reload from
+	ErrNoTableSource = errors.New("Table has no source to reload from")
+
+	// ErrInvalidTableLine is returned when line of routing table has
+	// wrong number of fields
+	ErrInvalidTableLine = errors.New("Wrong number of fields in routing table line")
+)
+
+// TableFormat is format of routing table
+type TableFormat string
+
+// Routing table formats supported by WithTableFormat
+const (
+	// TableFormatText is "<prefix>/<length> <asn>" per line. Fields are
+	// separated by any amount of spaces or tabs and further columns after
+	// ASN are ignored.
+	TableFormatText TableFormat = "text"
+
+	// TableFormatPfx2as is CAIDA Routeviews prefix to AS format,
+	// "<address>\t<length>\t<origin>" per line
+	TableFormatPfx2as TableFormat = "pfx2as"
+
+	// TableFormatMRT is MRT TABLE_DUMP_V2 RIB dump, optionally gzip or
+	// bzip2 compressed, see MRTReader
+	TableFormatMRT TableFormat = "mrt"
+)
+
+// Table holds IPv4 and IPv6 routes loaded from a routing table and answers
+// lookups for target IP addresses. Table is not tied to command line
+// arguments or environment variables. Lookups are safe for concurrent use,
+// also while table is being reloaded or updated.
+type Table struct {
+	// data holds *tableData. It is replaced as a whole on reload, so
+	// every lookup sees one consistent trie and index. Delete and Update
+	// change current tableData in place under its lock.
+	data atomic.Value
+
+	source        tableSource
+	name          string
+	opts          []Option
+	translateIPv4 bool
+	reloadMu      sync.Mutex
+
+	statusMu sync.Mutex
+	status   TableStatus
+}
+
+// tableData holds everything built from one routing table. Lookups hold
+// read lock of mu, Delete and Update hold write lock.
+type tableData struct {
+	mu          sync.RWMutex
+	trie        RouteTrie
+	index       asnIndex
+	routes      int
+	asSetRoutes int
+	skipped     map[string]int
+}
+
+// tableSource opens routing table for (re)loading
+type tableSource func(ctx context.Context) (io.ReadCloser, error)
+
+// TableStatus reports when Table was loaded and outcome of last reload
+type TableStatus struct {
+	// Routes is number of routes in current table
+	Routes int
+
+	// Skipped is number of lines of current table skipped because they
+	// could not be parsed, by reason
+	Skipped map[string]int
+
+	// ASSetRoutes is number of routes of current table with AS_SET origin
+	ASSetRoutes int
+
+	// LoadedAt is time when current table was loaded
+	LoadedAt time.Time
+
+	// LastReload is time of last reload attempt, zero if there was none
+	LastReload time.Time
+
+	// LastReloadError is error of last reload attempt, nil if it succeeded
+	LastReloadError error
+}
+
+// Age returns time since current table was loaded
+func (s TableStatus) Age() time.Duration {
+	return time.Since(s.LoadedAt)
+}
+
+// Option configures how Table is built
+type Option func(*tableOptions)
+
+type tableOptions struct {
+	format         TableFormat
+	strict         bool
+	httpClient     *http.Client
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	retries        int
+	retryBackoff   time.Duration
+	maxTableSize   int64
+	cacheDir       string
+	cacheMaxAge    time.Duration
+	warn           func(error)
+	newTrie        func() RouteTrie
+	onInsert       func(IPAddress)
+	translateIPv4  bool
+	insertPolicy   InsertPolicy
+}
+
+// WithTableFormat sets format of routing table. By default
+// TableFormatText is used.
+func WithTableFormat(format TableFormat) Option {
+	return func(o *tableOptions) {
+		o.format = format
+	}
+}
+
+// WithHTTPClient sets HTTP client used by FetchTable. By default a client
+// with connect timeout set by WithTimeouts is used.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *tableOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithBinaryTrie stores routes in bit by bit binary Trie instead of
+// default path compressed RadixTrie
+func WithBinaryTrie() Option {
+	return func(o *tableOptions) {
+		o.newTrie = func() RouteTrie {
+			trie := NewTrie()
+			trie.Policy = o.insertPolicy
+			return trie
+		}
+	}
+}
+
+// withInsertHook sets function called for every address inserted into trie
+func withInsertHook(onInsert func(IPAddress)) Option {
+	return func(o *tableOptions) {
+		o.onInsert = onInsert
+	}
+}
+
+func newTableOptions(opts []Option) *tableOptions {
+	o := &tableOptions{
+		format:         TableFormatText,
+		connectTimeout: DefaultConnectTimeout,
+		readTimeout:    DefaultReadTimeout,
+		retries:        DefaultRetries,
+		retryBackoff:   DefaultRetryBackoff,
+		maxTableSize:   DefaultMaxTableSize,
+		cacheMaxAge:    DefaultCacheMaxAge,
+	}
+	o.newTrie = func() RouteTrie {
+		trie := NewRadixTrie()
+		trie.Policy = o.insertPolicy
+		return trie
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// NewTable builds Table from routing table read from "r". By default each
+// line of routing table is "<prefix>/<length> <asn>", see WithTableFormat
+// for other formats. Lines which can not be parsed are skipped and counted
+// in TableStatus.Skipped, see WithStrict to fail instead. Table built from
+// a reader can not be reloaded.
+func NewTable(r io.Reader, opts ...Option) (*Table, error) {
+	o := newTableOptions(opts)
+	data, err := buildTableData(r, "", o)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{
+		opts:          opts,
+		translateIPv4: o.translateIPv4,
+	}
+	t.setData(data)
+	return t, nil
+}
+
+// LoadTable builds Table from routing table file at "path". Table is
+// reloaded from same file by Reload.
+func LoadTable(path string, opts ...Option) (*Table, error) {
+	return newTableFromSource(context.Background(), path, func(context.Context) (io.ReadCloser, error) {
+		return os.Open(path)
+	}, opts)
+}
+
+// FetchTable builds Table from routing table fetched from "url". Table is
+// fetched again from same URL by Reload. Downloads time out and are
+// retried, see WithTimeouts and WithRetries. See WithCacheDir to keep a
+// local copy of the table between runs.
+func FetchTable(url string, opts ...Option) (*Table, error) {
+	return FetchTableContext(context.Background(), url, opts...)
+}
+
+// FetchTableContext is like FetchTable, but download is canceled when
+// "ctx" is done
+func FetchTableContext(ctx context.Context, url string, opts ...Option) (*Table, error) {
+	o := newTableOptions(opts)
+
+	return newTableFromSource(ctx, url, func(ctx context.Context) (io.ReadCloser, error) {
+		if o.cacheDir != "" {
+			return fetchCached(ctx, url, o)
+		}
+
+		resp, err := fetch(ctx, url, nil, o)
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Body, nil
+	}, opts)
+}
+
+// newTableFromSource builds Table from routing table "name" opened by "source"
+func newTableFromSource(ctx context.Context, name string, source tableSource, opts []Option) (*Table, error) {
+	o := newTableOptions(opts)
+	data, err := source.build(ctx, name, o)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{
+		source:        source,
+		name:          name,
+		opts:          opts,
+		translateIPv4: o.translateIPv4,
+	}
+	t.setData(data)
+	return t, nil
+}
+
+// build opens routing table and builds tableData from it
+func (source tableSource) build(ctx context.Context, name string, o *tableOptions) (*tableData, error) {
+	reader, err := source(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return buildTableData(reader, name, o)
+}
+
+// buildTableData parses routing table read from "r" and inserts every
+// route into a new trie and ASN index. "name" is file name used in
+// ParseErrors.
+func buildTableData(r io.Reader, name string, o *tableOptions) (*tableData, error) {
+	if !o.insertPolicy.valid() {
+		return nil, ErrUnknownInsertPolicy
+	}
+
+	data := &tableData{
+		trie:    o.newTrie(),
+		index:   asnIndex{},
+		skipped: map[string]int{},
+	}
+
+	err := parseTable(r, name, o, func(ipAddress IPAddress) {
+		if len(ipAddress.GetASSet()) > 0 {
+			data.asSetRoutes++
+		}
+		data.insert(ipAddress, o)
+	}, func(pe *ParseError) error {
+		if o.strict {
+			return pe
+		}
+		data.skipped[pe.Err.Error()]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// insert adds route "ipAddress" into trie and ASN index. Routes which
+// insert policy does not store are ignored, and routes they replace are
+// removed from the index.
+func (data *tableData) insert(ipAddress IPAddress, o *tableOptions) {
+	stored, replaced := data.trie.Insert(ipAddress)
+	for _, info := range replaced {
+		data.index.remove(info)
+		data.routes--
+	}
+	if !stored {
+		return
+	}
+
+	data.index.add(ipAddress)
+	data.routes++
+	if o.onInsert != nil {
+		o.onInsert(ipAddress)
+	}
+}
+
+// parseTextLine parses "<prefix>/<length> <asn>" line of routing table.
+// Fields are separated by any whitespace. Columns after ASN are ignored.
+// ASN can be in any notation accepted by ParseASN.
+func parseTextLine(line string) ([]IPAddress, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return nil, ErrInvalidTableLine
+	}
+
+	// Prefix is checked first, so line with both fields bad is reported
+	// as bad prefix
+	if !isValidIPv4Cidr(parts[0]) && !isValidIPv6Cidr(parts[0]) {
+		return nil, ErrInvalidTablePrefix
+	}
+
+	asn, err := ParseASN(parts[1])
+	if err != nil {
+		return nil, ErrInvalidTableASN
+	}
+
+	ipAddress, err := newRouteAddress(parts[0], int(asn))
+	if err != nil {
+		return nil, err
+	}
+
+	return []IPAddress{ipAddress}, nil
+}
+
+// NewRoute returns route for "prefix" in CIDR format (8.8.8.0/24)
+// originated by "asn". Address bits beyond prefix length are cleared.
+// Route is used to insert, update, delete and walk routes of Trie and
+// RadixTrie.
+func NewRoute(prefix string, asn ASN) (IPAddress, error) {
+	return newRouteAddress(prefix, int(asn))
+}
+
+// newRouteAddress returns IPv4 or IPv6 address for route "ipCidr"
+// originated by "asn"
+func newRouteAddress(ipCidr string, asn int) (IPAddress, error) {
+	if isValidIPv4Cidr(ipCidr) {
+		return newIPv4Address(ipCidr, asn)
+	} else if isValidIPv6Cidr(ipCidr) {
+		return newIPv6Address(ipCidr, asn)
+	}
+
+	return nil, ErrInvalidTablePrefix
+}
+
+// load returns current tableData
+func (t *Table) load() *tableData {
+	return t.data.Load().(*tableData)
+}
+
+// rlock returns current tableData locked for reading. Caller must call
+// data.mu.RUnlock when done.
+func (t *Table) rlock() *tableData {
+	data := t.load()
+	data.mu.RLock()
+	return data
+}
+
+// setData swaps in "data" as current tableData
+func (t *Table) setData(data *tableData) {
+	t.data.Store(data)
+
+	t.statusMu.Lock()
+	defer t.statusMu.Unlock()
+	t.status.Routes = data.routes
+	t.status.Skipped = data.skipped
+	t.status.ASSetRoutes = data.asSetRoutes
+	t.status.LoadedAt = time.Now()
+}
+
+// Lookup returns NodeInfoList with all routes matching IPv4 or IPv6
+// address "addr", sorted by Cidr length. It returns ErrInvalidInputIPAddress
+// if "addr" is not a valid IP address.
+func (t *Table) Lookup(addr string) (NodeInfoList, error) {
+	ipToFind, err := newIPToFind(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	data := t.rlock()
+	defer data.mu.RUnlock()
+	return data.trie.Find(ipToFind), nil
+}
+
+// LongestMatch looks up target IP address "addr" and returns only its
+// most specific matching route. It returns false if no route matches.
+func (t *Table) LongestMatch(addr string) (NodeInfo, bool, error) {
+	ipToFind, err := newIPToFind(addr)
+	if err != nil {
+		return NodeInfo{}, false, err
+	}
+
+	data := t.rlock()
+	defer data.mu.RUnlock()
+	info, ok := data.trie.LongestMatch(ipToFind)
+	return info, ok, nil
+}
+
+// Delete withdraws routes of "prefix" in CIDR format originated by "asn"
+// (first member for AS_SET origin) and returns them. Changes made by
+// Delete and Update are lost when table is reloaded, unless table source
+// has them too.
+func (t *Table) Delete(prefix string, asn ASN) (NodeInfoList, error) {
+	ipAddress, err := NewRoute(prefix, asn)
+	if err != nil {
+		return nil, err
+	}
+
+	data := t.load()
+	data.mu.Lock()
+	removed := data.trie.Delete(ipAddress, asn)
+	data.removed(removed)
+	data.mu.Unlock()
+
+	t.setCounts(data)
+	return removed, nil
+}
+
+// Update makes "asn" the only origin of "prefix" in CIDR format, adding
+// the route if table does not have the prefix. It returns routes with
+// same prefix it replaced.
+func (t *Table) Update(prefix string, asn ASN) (NodeInfoList, error) {
+	ipAddress, err := NewRoute(prefix, asn)
+	if err != nil {
+		return nil, err
+	}
+
+	data := t.load()
+	data.mu.Lock()
+	replaced := data.trie.Update(ipAddress)
+	data.removed(replaced)
+	data.index.add(ipAddress)
+	data.routes++
+	data.mu.Unlock()
+
+	t.setCounts(data)
+	return replaced, nil
+}
+
+// removed removes routes "infos" which were removed from trie from ASN
+// index and route counts
+func (data *tableData) removed(infos NodeInfoList) {
+	for _, info := range infos {
+		data.index.remove(info)
+		data.routes--
+		if len(info.ASSet) > 0 {
+			data.asSetRoutes--
+		}
+	}
+}
+
+// setCounts updates route counts of TableStatus from "data" after Delete
+// or Update. Status of a table swapped in by reload meanwhile is kept.
+func (t *Table) setCounts(data *tableData) {
+	data.mu.RLock()
+	defer data.mu.RUnlock()
+
+	t.statusMu.Lock()
+	defer t.statusMu.Unlock()
+	if t.load() == data {
+		t.status.Routes = data.routes
+		t.status.ASSetRoutes = data.asSetRoutes
+	}
+}
+
+// Reload builds new trie from the file or URL Table was loaded from and
+// swaps it in atomically. Lookups running during reload use previous
+// trie. If reload fails, previous trie is kept. Concurrent calls to
+// Reload are serialized.
+func (t *Table) Reload() error {
+	return t.ReloadContext(context.Background())
+}
+
+// ReloadContext is like Reload, but download of the table is canceled
+// when "ctx" is done
+func (t *Table) ReloadContext(ctx context.Context) error {
+	t.reloadMu.Lock()
+	defer t.reloadMu.Unlock()
+
+	err := ErrNoTableSource
+	var data *tableData
+	if t.source != nil {
+		data, err = t.source.build(ctx, t.name, newTableOptions(t.opts))
+	}
+
+	if err == nil {
+		t.setData(data)
+	}
+
+	t.statusMu.Lock()
+	defer t.statusMu.Unlock()
+	t.status.LastReload = time.Now()
+	t.status.LastReloadError = err
+	return err
+}
+
+// Status returns when current table was loaded and outcome of last reload
+func (t *Table) Status() TableStatus {
+	t.statusMu.Lock()
+	defer t.statusMu.Unlock()
+	return t.status
+}
+
+// AutoReload reloads Table every "interval" and whenever a value is
+// received on "trigger" until "ctx" is done. Zero interval or nil trigger
+// disables that kind of reload. "report" is called with result of every
+// reload if it is not nil. AutoReload blocks, so it is usually run in its
+// own goroutine.
+func (t *Table) AutoReload(ctx context.Context, interval time.Duration, trigger <-chan struct{}, report func(error)) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-trigger:
+		}
+
+		err := t.ReloadContext(ctx)
+		if report != nil {
+			report(err)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package asnlookup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+const tableTestText = `8.8.8.8/24 350
+8.0.0.0/9 352
+8.0.0.0/12 351
+192.121.43.0/24 156
+2604:a880:2:d0::2249:2001/64 440
+2604:a880:2:d0::2249:2001/65 444
+bad line
+10.0.0.0/33 100
+10.0.0.0/8 notanasn
+`
+
+func TestTableLookup(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ipToFind string
+		want     NodeInfoList
+		err      error
+	}{
+		{
+			name:     "Lookup IPv4 Address",
+			ipToFind: "8.8.8.8",
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+				{Subnet: "8.0.0.0", Cidr: 12, Asn: 351},
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+			err: nil,
+		},
+		{
+			name:     "Lookup IPv6 Address",
+			ipToFind: "2604:a880:2:d0::1",
+			want: NodeInfoList{
+				{Subnet: "2604:a880:2:d0::", Cidr: 65, Asn: 444},
+				{Subnet: "2604:a880:2:d0::", Cidr: 64, Asn: 440},
+			},
+			err: nil,
+		},
+		{
+			name:     "Lookup IPv4 Address Without Match",
+			ipToFind: "10.1.1.1",
+			want:     NodeInfoList{},
+			err:      nil,
+		},
+		{
+			name:     "Lookup Invalid Address",
+			ipToFind: "8.8.8",
+			want:     nil,
+			err:      ErrInvalidInputIPAddress,
+		},
+	}
+
+	// Both trie implementations must return same results
+	for _, opts := range [][]Option{nil, {WithBinaryTrie()}} {
+		table, err := NewTable(strings.NewReader(tableTestText), opts...)
+		if err != nil {
+			t.Fatalf("received error does not match: got %v, want %v", err, nil)
+		}
+
+		for _, testCase := range testCases {
+			got, err := table.Lookup(testCase.ipToFind)
+			if err != testCase.err {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+			}
+
+			if reflect.DeepEqual(got, testCase.want) != true {
+				t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+			}
+		}
+	}
+}
+
+func TestTableLongestMatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ipToFind string
+		want     NodeInfo
+		found    bool
+		err      error
+	}{
+		{
+			name:     "Longest Match IPv4 Address",
+			ipToFind: "8.8.8.8",
+			want:     NodeInfo{Subnet: "8.8.8.0", Cidr: 24, Asn: 350},
+			found:    true,
+			err:      nil,
+		},
+		{
+			name:     "Longest Match IPv6 Address",
+			ipToFind: "2604:a880:2:d0::1",
+			want:     NodeInfo{Subnet: "2604:a880:2:d0::", Cidr: 65, Asn: 444},
+			found:    true,
+			err:      nil,
+		},
+		{
+			name:     "Longest Match IPv4 Address Without Match",
+			ipToFind: "10.1.1.1",
+			want:     NodeInfo{},
+			found:    false,
+			err:      nil,
+		},
+		{
+			name:     "Longest Match Invalid Address",
+			ipToFind: "8.8.8",
+			want:     NodeInfo{},
+			found:    false,
+			err:      ErrInvalidInputIPAddress,
+		},
+	}
+
+	for _, opts := range [][]Option{nil, {WithBinaryTrie()}} {
+		table, err := NewTable(strings.NewReader(tableTestText), opts...)
+		if err != nil {
+			t.Fatalf("received error does not match: got %v, want %v", err, nil)
+		}
+
+		for _, testCase := range testCases {
+			got, found, err := table.LongestMatch(testCase.ipToFind)
+			if err != testCase.err {
+				t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.err)
+			}
+
+			if found != testCase.found || reflect.DeepEqual(got, testCase.want) != true {
+				t.Fatalf("%s: result does not match: got %v %v, want %v %v", testCase.name, got, found, testCase.want, testCase.found)
+			}
+		}
+	}
+}
+
+func TestNewTableReadError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	r := io.MultiReader(strings.NewReader("8.8.8.0/24 350\n"), iotest.ErrReader(readErr))
+
+	_, err := NewTable(r)
+	if err != readErr {
+		t.Fatalf("received error does not match: got %v, want %v", err, readErr)
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// TestTextFormatGolden parses variants of text routing table found in
+// exported tables and compares parsed routes and bad lines with golden
+// files. Run "go test -run TestTextFormatGolden -update" to update them.
+func TestTextFormatGolden(t *testing.T) {
+	inputs, err := filepath.Glob("./testdata/text/*.txt")
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("received error does not match: got %v, want golden test inputs", err)
+	}
+
+	for _, input := range inputs {
+		file, err := os.Open(input)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", input, err, nil)
+		}
+
+		var got bytes.Buffer
+		err = parseTable(file, filepath.Base(input), newTableOptions(nil), func(ip IPAddress) {
+			info := newNodeInfo(ip)
+			fmt.Fprintf(&got, "%s/%d %s\n", info.Subnet, info.Cidr, info.Origin())
+		}, func(pe *ParseError) error {
+			fmt.Fprintf(&got, "error: %s\n", pe)
+			return nil
+		})
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", input, err, nil)
+		}
+
+		golden := strings.TrimSuffix(input, ".txt") + ".golden"
+		if *updateGolden {
+			ioutil.WriteFile(golden, got.Bytes(), 0644)
+		}
+
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", golden, err, nil)
+		}
+
+		if got.String() != string(want) {
+			t.Fatalf("%s: result does not match: got\n%s\nwant\n%s", input, got.String(), want)
+		}
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	table, err := LoadTable("./config_file_test.txt")
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	want := NodeInfoList{{Subnet: "192.121.43.0", Cidr: 24, Asn: 156}}
+	got, _ := table.Lookup("192.121.43.7")
+	if reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+
+	_, err = LoadTable("./does_not_exist.txt")
+	if err == nil {
+		t.Fatalf("received error does not match: got %v, want non-nil error", err)
+	}
+}
+
+func TestFetchTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/table.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(tableTestText))
+	}))
+	defer server.Close()
+
+	table, err := FetchTable(server.URL+"/table.txt", WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	want := NodeInfoList{{Subnet: "192.121.43.0", Cidr: 24, Asn: 156}}
+	got, _ := table.Lookup("192.121.43.7")
+	if reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+
+	_, err = FetchTable(server.URL + "/missing.txt")
+	if err == nil {
+		t.Fatalf("received error does not match: got %v, want non-nil error", err)
+	}
+}
+
+func TestTableReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "table.txt")
+	if err := ioutil.WriteFile(path, []byte("8.8.8.0/24 350\n"), 0644); err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	table, err := LoadTable(path)
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	testCases := []struct {
+		name      string
+		setUpFunc func()
+		want      NodeInfoList
+		wantErr   bool
+	}{
+		{
+			name: "Reload Changed Table",
+			setUpFunc: func() {
+				ioutil.WriteFile(path, []byte("8.8.8.0/24 351\n8.0.0.0/9 352\n"), 0644)
+			},
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 351},
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Reload Missing Table Keeps Previous Table",
+			setUpFunc: func() {
+				os.Remove(path)
+			},
+			want: NodeInfoList{
+				{Subnet: "8.8.8.0", Cidr: 24, Asn: 351},
+				{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.setUpFunc()
+		err := table.Reload()
+		if (err != nil) != testCase.wantErr {
+			t.Fatalf("%s: received error does not match: got %v, want error %v", testCase.name, err, testCase.wantErr)
+		}
+
+		status := table.Status()
+		if status.LastReloadError != err || status.LastReload.IsZero() {
+			t.Fatalf("%s: received status does not match: got %v, want error %v", testCase.name, status, err)
+		}
+
+		got, _ := table.Lookup("8.8.8.8")
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+
+	table, _ = NewTable(strings.NewReader(tableTestText))
+	if err := table.Reload(); err != ErrNoTableSource {
+		t.Fatalf("received error does not match: got %v, want %v", err, ErrNoTableSource)
+	}
+}
+
+// TestTableReloadConcurrentLookup checks that lookups running during
+// reloads always see either previous or new table, never a mix of both
+func TestTableReloadConcurrentLookup(t *testing.T) {
+	tables := []string{
+		"8.8.8.0/24 350\n8.0.0.0/9 352\n",
+		"8.8.8.0/24 450\n8.0.0.0/9 452\n",
+	}
+
+	path := filepath.Join(t.TempDir(), "table.txt")
+	ioutil.WriteFile(path, []byte(tables[0]), 0644)
+	table, err := LoadTable(path)
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	trigger := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		table.AutoReload(ctx, 0, trigger, nil)
+		close(done)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				got, _ := table.Lookup("8.8.8.8")
+				if len(got) != 2 || got[1].Asn != got[0].Asn+2 {
+					t.Errorf("result does not match: got %v, want one consistent table", got)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		ioutil.WriteFile(path, []byte(tables[i%2]), 0644)
+		trigger <- struct{}{}
+	}
+
+	wg.Wait()
+	cancel()
+	<-done
+}
+
+func TestTableDeleteUpdate(t *testing.T) {
+	table, err := NewTable(strings.NewReader("8.8.8.0/24 350\n8.8.8.0/24 351\n8.0.0.0/9 352\n"))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	testCases := []struct {
+		name       string
+		change     func() (NodeInfoList, error)
+		want       NodeInfoList
+		wantErr    error
+		wantRoutes int
+		prefixes   map[ASN]int
+	}{
+		{
+			name:       "Delete Origin",
+			change:     func() (NodeInfoList, error) { return table.Delete("8.8.8.0/24", 350) },
+			want:       NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 350}},
+			wantRoutes: 2,
+			prefixes:   map[ASN]int{350: 0, 351: 1, 352: 1},
+		},
+		{
+			name:       "Delete Missing Origin",
+			change:     func() (NodeInfoList, error) { return table.Delete("8.8.8.0/24", 350) },
+			want:       nil,
+			wantRoutes: 2,
+			prefixes:   map[ASN]int{350: 0, 351: 1, 352: 1},
+		},
+		{
+			name:       "Update Replaces Origin",
+			change:     func() (NodeInfoList, error) { return table.Update("8.8.8.0/24", 353) },
+			want:       NodeInfoList{{Subnet: "8.8.8.0", Cidr: 24, Asn: 351}},
+			wantRoutes: 2,
+			prefixes:   map[ASN]int{351: 0, 352: 1, 353: 1},
+		},
+		{
+			name:       "Update Adds Prefix",
+			change:     func() (NodeInfoList, error) { return table.Update("8.8.4.7/24", 353) },
+			want:       nil,
+			wantRoutes: 3,
+			prefixes:   map[ASN]int{352: 1, 353: 2},
+		},
+		{
+			name:       "Delete Invalid Prefix",
+			change:     func() (NodeInfoList, error) { return table.Delete("8.8.8.8", 353) },
+			wantErr:    ErrInvalidTablePrefix,
+			wantRoutes: 3,
+			prefixes:   map[ASN]int{352: 1, 353: 2},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got, err := testCase.change()
+		if err != testCase.wantErr {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.wantErr)
+		}
+
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+
+		if routes := table.Status().Routes; routes != testCase.wantRoutes {
+			t.Fatalf("%s: routes do not match: got %v, want %v", testCase.name, routes, testCase.wantRoutes)
+		}
+
+		for asn, want := range testCase.prefixes {
+			if got := len(table.Prefixes(asn)); got != want {
+				t.Fatalf("%s: prefixes of %v do not match: got %v, want %v", testCase.name, asn, got, want)
+			}
+		}
+	}
+
+	got, _ := table.Lookup("8.8.8.8")
+	want := NodeInfoList{
+		{Subnet: "8.8.8.0", Cidr: 24, Asn: 353},
+		{Subnet: "8.0.0.0", Cidr: 9, Asn: 352},
+	}
+	if reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+}
+
+// TestTableUpdateConcurrentLookup checks that lookups running during
+// updates and reloads always see a whole route
+func TestTableUpdateConcurrentLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table.txt")
+	ioutil.WriteFile(path, []byte("8.8.8.0/24 350\n8.0.0.0/9 352\n"), 0644)
+	table, err := LoadTable(path)
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				got, _ := table.Lookup("8.8.8.8")
+				if len(got) == 0 || got[len(got)-1].Asn != 352 {
+					t.Errorf("result does not match: got %v, want route of 352 last", got)
+					return
+				}
+				table.Prefixes(350)
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		switch i % 3 {
+		case 0:
+			table.Update("8.8.8.0/24", ASN(400+i))
+		case 1:
+			table.Delete("8.8.8.0/24", ASN(400+i-1))
+		default:
+			table.Reload()
+		}
+	}
+
+	wg.Wait()
+}
+
+// BenchmarkNewTable1M measures time and peak heap memory to load synthetic
+// table with 1M routes. Table is generated while it is read, so reported
+// peak heap is used by parser and trie only.
+func BenchmarkNewTable1M(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+
+		done := make(chan struct{})
+		peak := make(chan uint64)
+		go func() {
+			var max uint64
+			var m runtime.MemStats
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					peak <- max
+					return
+				case <-ticker.C:
+					runtime.ReadMemStats(&m)
+					if m.HeapAlloc > max {
+						max = m.HeapAlloc
+					}
+				}
+			}
+		}()
+
+		if _, err := NewTable(&syntheticTable{lines: 1000000}); err != nil {
+			b.Fatalf("received error does not match: got %v, want %v", err, nil)
+		}
+
+		close(done)
+		b.ReportMetric(float64(<-peak-before.HeapAlloc)/(1<<20), "peak-heap-MB")
+	}
+}
+
+// syntheticTable generates routing table with "lines" routes as it is
+// read. Every tenth route is IPv6.
+type syntheticTable struct {
+	lines int
+	n     int
+	buf   bytes.Buffer
+}
+
+func (s *syntheticTable) Read(p []byte) (int, error) {
+	for s.buf.Len() < len(p) && s.n < s.lines {
+		if s.n%10 == 9 {
+			fmt.Fprintf(&s.buf, "2001:%x:%x::/48 %d\n", s.n>>16, s.n&0xffff, 64512+s.n%1000)
+		} else {
+			fmt.Fprintf(&s.buf, "%d.%d.%d.0/24 %d\n", 1+s.n>>16, (s.n>>8)&0xff, s.n&0xff, 64512+s.n%1000)
+		}
+		s.n++
+	}
+
+	if s.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+
+	return s.buf.Read(p)
+}
@@ -0,0 +1,90 @@
+package asnlookup
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kinds of IPv6 addresses with embedded IPv4 address which are looked up
+// in IPv4 table with WithIPv4Translation
+const (
+	// TranslationIPv4Mapped is IPv4-mapped address ::ffff:0:0/96 (RFC 4291)
+	TranslationIPv4Mapped = "ipv4-mapped"
+
+	// TranslationNAT64 is address of NAT64 well-known prefix 64:ff9b::/96
+	// (RFC 6052)
+	TranslationNAT64 = "nat64"
+)
+
+// nat64Prefix is highest 64 bits of NAT64 well-known prefix 64:ff9b::/96
+const nat64Prefix = 0x0064ff9b00000000
+
+// Translation tells that IPv6 query was also looked up as IPv4 address
+// embedded in it. Kind is TranslationIPv4Mapped or TranslationNAT64.
+type Translation struct {
+	Kind string `json:"kind"`
+	IPv4 string `json:"ipv4"`
+}
+
+func (tr Translation) String() string {
+	kind := "IPv4-mapped"
+	if tr.Kind == TranslationNAT64 {
+		kind = "NAT64"
+	}
+
+	return fmt.Sprintf("translated to %s (%s)", tr.IPv4, kind)
+}
+
+// WithIPv4Translation makes Table.Query look up IPv4 address embedded in
+// IPv4-mapped (::ffff:192.0.2.1) and NAT64 (64:ff9b::192.0.2.1) queries in
+// IPv4 table too. IPv4 matches come first in the Result and are marked as
+// translated.
+func WithIPv4Translation() Option {
+	return func(o *tableOptions) {
+		o.translateIPv4 = true
+	}
+}
+
+// translateIPv4 returns Translation and IPv4 address embedded in "ip" if
+// it is IPv4-mapped or NAT64 address, nil otherwise. Prefix of /96 or
+// longer is translated to IPv4 prefix with 96 bits shorter length; shorter
+// prefixes do not lie within one embedded IPv4 address space and are not
+// translated.
+func translateIPv4(ip IPAddress) (*Translation, IPAddress) {
+	ipv6, ok := ip.(IPv6Address)
+	if !ok {
+		return nil, nil
+	}
+
+	cidr := ipv6.GetCidrLen() - 96
+	if cidr < 0 {
+		return nil, nil
+	}
+
+	tr := &Translation{}
+	switch {
+	case ipv6.ip[0] == 0 && ipv6.ip[1]>>32 == 0xFFFF:
+		tr.Kind = TranslationIPv4Mapped
+	case ipv6.ip[0] == nat64Prefix && ipv6.ip[1]>>32 == 0:
+		tr.Kind = TranslationNAT64
+	default:
+		return nil, nil
+	}
+
+	ipv4Str, err := intToIPv4Str(uint32(ipv6.ip[1]))
+	if err != nil {
+		return nil, nil
+	}
+
+	ipv4, err := newIPv4Address(ipv4Str+"/"+strconv.Itoa(cidr), -1)
+	if err != nil {
+		return nil, nil
+	}
+
+	// Host queries stay addresses, prefix queries stay prefixes
+	tr.IPv4 = ipv4Str
+	if cidr < 32 {
+		tr.IPv4 += "/" + strconv.Itoa(cidr)
+	}
+	return tr, ipv4
+}
@@ -0,0 +1,179 @@
+package asnlookup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTranslateIPv4(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ipToFind string
+		want     *Translation
+	}{
+		{
+			name:     "Translate IPv4-mapped Address",
+			ipToFind: "::ffff:192.0.2.1",
+			want:     &Translation{Kind: TranslationIPv4Mapped, IPv4: "192.0.2.1"},
+		},
+		{
+			name:     "Translate IPv4-mapped Address In Hex",
+			ipToFind: "::ffff:c000:201",
+			want:     &Translation{Kind: TranslationIPv4Mapped, IPv4: "192.0.2.1"},
+		},
+		{
+			name:     "Translate NAT64 Address",
+			ipToFind: "64:ff9b::198.51.100.7",
+			want:     &Translation{Kind: TranslationNAT64, IPv4: "198.51.100.7"},
+		},
+		{
+			name:     "Translate IPv4-mapped Prefix",
+			ipToFind: "::ffff:192.0.2.0/120",
+			want:     &Translation{Kind: TranslationIPv4Mapped, IPv4: "192.0.2.0/24"},
+		},
+		{
+			name:     "Translate NAT64 Prefix",
+			ipToFind: "64:ff9b::/96",
+			want:     &Translation{Kind: TranslationNAT64, IPv4: "0.0.0.0/0"},
+		},
+		{
+			name:     "Do Not Translate Prefix Shorter Than 96",
+			ipToFind: "::ffff:0:0/95",
+			want:     nil,
+		},
+		{
+			name:     "Do Not Translate IPv4-compatible Address",
+			ipToFind: "::192.0.2.1",
+			want:     nil,
+		},
+		{
+			name:     "Do Not Translate NAT64 Address With Non Zero Suffix",
+			ipToFind: "64:ff9b::1:c633:6407",
+			want:     nil,
+		},
+		{
+			name:     "Do Not Translate IPv4 Address",
+			ipToFind: "192.0.2.1",
+			want:     nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		ipToFind, err := newIPToFind(testCase.ipToFind)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		got, ipv4 := translateIPv4(ipToFind)
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+
+		if got != nil && ipv4.GetString() != hostAddress(got.IPv4) {
+			t.Fatalf("%s: IPv4 address does not match: got %v, want %v", testCase.name, ipv4.GetString(), got.IPv4)
+		}
+	}
+}
+
+func TestTableQueryTranslation(t *testing.T) {
+	const tableText = `192.0.2.0/24 64500
+::ffff:0:0/96 64501
+64:ff9b::/96 64502
+`
+
+	testCases := []struct {
+		name  string
+		opts  []Option
+		query string
+		want  Result
+	}{
+		{
+			name:  "Query IPv4-mapped Address Without Translation",
+			query: "::ffff:192.0.2.1",
+			want: Result{
+				Query:   "::ffff:192.0.2.1",
+				Special: &SpecialPurpose{"IPv4-mapped Address", "RFC 4291"},
+				Matches: []Match{
+					{Prefix: "::ffff:0.0.0.0/96", Subnet: "::ffff:0.0.0.0", Cidr: 96, Asn: 64501, Longest: true,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"IPv4-mapped Address", "RFC 4291"}}},
+				},
+			},
+		},
+		{
+			name:  "Query IPv4-mapped Address With Translation",
+			opts:  []Option{WithIPv4Translation()},
+			query: "::ffff:192.0.2.1",
+			want: Result{
+				Query:       "::ffff:192.0.2.1",
+				Special:     &SpecialPurpose{"IPv4-mapped Address", "RFC 4291"},
+				Translation: &Translation{Kind: TranslationIPv4Mapped, IPv4: "192.0.2.1"},
+				Matches: []Match{
+					{Prefix: "192.0.2.0/24", Subnet: "192.0.2.0", Cidr: 24, Asn: 64500, Longest: true, Translated: true,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"Documentation (TEST-NET-1)", "RFC 5737"}}},
+					{Prefix: "::ffff:0.0.0.0/96", Subnet: "::ffff:0.0.0.0", Cidr: 96, Asn: 64501, Longest: false,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"IPv4-mapped Address", "RFC 4291"}}},
+				},
+			},
+		},
+		{
+			name:  "Query IPv4-mapped Prefix With Translation",
+			opts:  []Option{WithIPv4Translation()},
+			query: "::ffff:192.0.2.0/120",
+			want: Result{
+				Query:       "::ffff:192.0.2.0/120",
+				Special:     &SpecialPurpose{"IPv4-mapped Address", "RFC 4291"},
+				Translation: &Translation{Kind: TranslationIPv4Mapped, IPv4: "192.0.2.0/24"},
+				Matches: []Match{
+					{Prefix: "192.0.2.0/24", Subnet: "192.0.2.0", Cidr: 24, Asn: 64500, Longest: true, Translated: true,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"Documentation (TEST-NET-1)", "RFC 5737"}}},
+					{Prefix: "::ffff:0.0.0.0/96", Subnet: "::ffff:0.0.0.0", Cidr: 96, Asn: 64501, Longest: false,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"IPv4-mapped Address", "RFC 4291"}}},
+				},
+			},
+		},
+		{
+			name:  "Query NAT64 Address With Translation Without IPv4 Match",
+			opts:  []Option{WithIPv4Translation()},
+			query: "64:ff9b::198.51.100.7",
+			want: Result{
+				Query:       "64:ff9b::198.51.100.7",
+				Special:     &SpecialPurpose{"IPv4-IPv6 Translation", "RFC 6052"},
+				Translation: &Translation{Kind: TranslationNAT64, IPv4: "198.51.100.7"},
+				Matches: []Match{
+					{Prefix: "64:ff9b::/96", Subnet: "64:ff9b::", Cidr: 96, Asn: 64502, Longest: true,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"IPv4-IPv6 Translation", "RFC 6052"}}},
+				},
+			},
+		},
+		{
+			name:  "Query IPv4 Address With Translation",
+			opts:  []Option{WithIPv4Translation()},
+			query: "192.0.2.1",
+			want: Result{
+				Query:   "192.0.2.1",
+				Special: &SpecialPurpose{"Documentation (TEST-NET-1)", "RFC 5737"},
+				Matches: []Match{
+					{Prefix: "192.0.2.0/24", Subnet: "192.0.2.0", Cidr: 24, Asn: 64500, Longest: true,
+						Special: &Classification{ASN: &SpecialPurpose{"Documentation", "RFC 5398"}, Prefix: &SpecialPurpose{"Documentation (TEST-NET-1)", "RFC 5737"}}},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		table, err := NewTable(strings.NewReader(tableText), testCase.opts...)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		got, err := table.Query(testCase.query)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: result does not match: got %+v, want %+v", testCase.name, got, testCase.want)
+		}
+	}
+}
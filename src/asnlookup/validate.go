@@ -0,0 +1,193 @@
+package asnlookup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrInvalidTablePrefix is returned when prefix of routing table line
+	// is not a valid IPv4 or IPv6 CIDR
+	ErrInvalidTablePrefix = errors.New("Invalid prefix in routing table line")
+
+	// ErrInvalidTableASN is returned when ASN of routing table line is
+	// not a number
+	ErrInvalidTableASN = errors.New("Invalid ASN in routing table line")
+
+	// ErrTableLineCR is returned when routing table line contains carriage
+	// return before its end, usually because table has CR line endings.
+	// CRLF line endings are accepted.
+	ErrTableLineCR = errors.New("Routing table line contains carriage return (CR line endings)")
+)
+
+// ParseError is returned for routing table line which can not be parsed.
+// Err is the reason, e.g. ErrInvalidTableLine or ErrInvalidTableASN. For
+// MRT dumps, Line is number of the MRT record and Text is the prefix.
+type ParseError struct {
+	File string
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %v: %q", e.Line, e.Err, e.Text)
+	}
+
+	return fmt.Sprintf("%s:%d: %v: %q", e.File, e.Line, e.Err, e.Text)
+}
+
+// Unwrap returns reason of ParseError
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// WithStrict makes loading fail with *ParseError on first routing table
+// line which can not be parsed. By default such lines are skipped and
+// counted in TableStatus.Skipped.
+func WithStrict() Option {
+	return func(o *tableOptions) {
+		o.strict = true
+	}
+}
+
+// ValidationResult is result of ValidateTable
+type ValidationResult struct {
+	// Routes is number of valid routes
+	Routes int
+
+	// ASSetRoutes is number of valid routes with AS_SET origin
+	ASSetRoutes int
+
+	// Errors has every line which can not be parsed
+	Errors []*ParseError
+}
+
+// ValidateTable checks routing table read from "r" without building a
+// trie. "name" is used as file name in ParseErrors. All bad lines are
+// returned, or only the first one with WithStrict. Error is returned only
+// if table can not be read.
+func ValidateTable(r io.Reader, name string, opts ...Option) (ValidationResult, error) {
+	o := newTableOptions(opts)
+	result := ValidationResult{}
+
+	err := parseTable(r, name, o, func(ipAddress IPAddress) {
+		result.Routes++
+		if len(ipAddress.GetASSet()) > 0 {
+			result.ASSetRoutes++
+		}
+	}, func(pe *ParseError) error {
+		result.Errors = append(result.Errors, pe)
+		if o.strict {
+			return pe
+		}
+		return nil
+	})
+
+	if _, ok := err.(*ParseError); ok {
+		err = nil
+	}
+	return result, err
+}
+
+// parseTable parses routing table read from "r" and calls "insert" for
+// every route. In all line based formats, "#" starts a comment which runs
+// to end of line, and blank lines are ignored. Lines which can not be
+// parsed are passed to "skip", and parsing stops if "skip" returns an
+// error. MRT dumps have no lines, so they are read with MRTReader and
+// prefixes it skips are passed to "skip".
+func parseTable(r io.Reader, name string, o *tableOptions, insert func(IPAddress), skip func(*ParseError) error) error {
+	if o.format == TableFormatMRT {
+		return parseMRT(r, name, insert, skip)
+	}
+
+	parseLine := parseTextLine
+	if o.format == TableFormatPfx2as {
+		parseLine = parsePfx2asLine
+	}
+
+	// Scan the table line by line as it is read, so whole table is never
+	// held in memory
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxTableLineLen)
+
+	// Table with CR line endings is one long line. It fails with
+	// ErrTableLineCR instead of bufio.ErrTooLong also when it is longer
+	// than maxTableLineLen.
+	var crText string
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance == 0 && err == nil && len(data) >= maxTableLineLen {
+			if i := bytes.IndexByte(data[:len(data)-1], '\r'); i >= 0 {
+				crText = string(data[:i])
+				return 0, nil, ErrTableLineCR
+			}
+		}
+		return advance, token, err
+	})
+
+	lineNum := 1
+	for ; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		content := strings.TrimRight(line, "\r")
+		if i := strings.Index(content, "#"); i >= 0 {
+			content = content[:i]
+		}
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		// Carriage return inside a line means CR line endings, which would
+		// join all lines of the table into one
+		var routes []IPAddress
+		err := ErrTableLineCR
+		if !strings.Contains(content, "\r") {
+			routes, err = parseLine(content)
+		}
+
+		if err != nil {
+			if err := skip(&ParseError{File: name, Line: lineNum, Text: line, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, ipAddress := range routes {
+			insert(ipAddress)
+		}
+	}
+
+	if err := scanner.Err(); err == ErrTableLineCR {
+		return skip(&ParseError{File: name, Line: lineNum, Text: crText, Err: err})
+	}
+	return scanner.Err()
+}
+
+// parseMRT calls "insert" for every route of MRT RIB dump read from "r"
+// and "skip" for every prefix which MRTReader skips
+func parseMRT(r io.Reader, name string, insert func(IPAddress), skip func(*ParseError) error) error {
+	mrt, err := NewMRTReader(r)
+	if err != nil {
+		return err
+	}
+
+	mrt.skip = func(record int, prefix string, err error) error {
+		return skip(&ParseError{File: name, Line: record, Text: prefix, Err: err})
+	}
+
+	for {
+		ipAddress, err := mrt.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		insert(ipAddress)
+	}
+}
@@ -0,0 +1,149 @@
+package asnlookup
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableStrict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		table    string
+		wantLine int
+		wantErr  error
+	}{
+		{
+			name:     "Fail On First Bad Line",
+			table:    tableTestText,
+			wantLine: 7,
+			wantErr:  ErrInvalidTablePrefix,
+		},
+		{
+			name:     "Fail On Wrong Number Of Fields",
+			table:    "8.8.8.0/24 350\n8.0.0.0/9\n",
+			wantLine: 2,
+			wantErr:  ErrInvalidTableLine,
+		},
+		{
+			name:     "Fail On Invalid Prefix",
+			table:    "8.8.8.0/24 350\n10.0.0.0/33 100\n",
+			wantLine: 2,
+			wantErr:  ErrInvalidTablePrefix,
+		},
+		{
+			name:     "Fail On Invalid Prefix And ASN",
+			table:    "10.0.0.0/33 x\n",
+			wantLine: 1,
+			wantErr:  ErrInvalidTablePrefix,
+		},
+		{
+			name:     "Fail On Invalid ASN",
+			table:    "10.0.0.0/8 notanasn\n",
+			wantLine: 1,
+			wantErr:  ErrInvalidTableASN,
+		},
+		{
+			name:     "Fail On CR Line Endings",
+			table:    "8.8.8.0/24 350\r8.0.0.0/9 352\r",
+			wantLine: 1,
+			wantErr:  ErrTableLineCR,
+		},
+		{
+			name:     "Fail On CR Line Endings Longer Than Line Limit",
+			table:    strings.Repeat("8.8.8.0/24 350\r", maxTableLineLen/10),
+			wantLine: 1,
+			wantErr:  ErrTableLineCR,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "table.txt")
+	for _, testCase := range testCases {
+		ioutil.WriteFile(path, []byte(testCase.table), 0644)
+		_, err := LoadTable(path, WithStrict())
+
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%s: received error does not match: got %v, want *ParseError", testCase.name, err)
+		}
+
+		if pe.File != path || pe.Line != testCase.wantLine || pe.Err != testCase.wantErr {
+			t.Fatalf("%s: received error does not match: got %v, want %s:%d: %v", testCase.name, pe, path, testCase.wantLine, testCase.wantErr)
+		}
+	}
+
+	// CRLF line endings are accepted
+	table, err := NewTable(strings.NewReader("8.8.8.0/24 350\r\n8.0.0.0/9 352\r\n"), WithStrict())
+	if err != nil || table.Status().Routes != 2 {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	// Long table with CR line endings is skipped as one bad line
+	table, err = NewTable(strings.NewReader(strings.Repeat("8.8.8.0/24 350\r", maxTableLineLen/10)))
+	want := map[string]int{ErrTableLineCR.Error(): 1}
+	if err != nil || reflect.DeepEqual(table.Status().Skipped, want) != true {
+		t.Fatalf("result does not match: got %v %v, want %v", err, table.Status().Skipped, want)
+	}
+}
+
+func TestTableSkipped(t *testing.T) {
+	table, err := NewTable(strings.NewReader(tableTestText))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	want := map[string]int{
+		ErrInvalidTablePrefix.Error(): 2,
+		ErrInvalidTableASN.Error():    1,
+	}
+	if got := table.Status().Skipped; reflect.DeepEqual(got, want) != true {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+}
+
+func TestValidateTable(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       []Option
+		wantRoutes int
+		wantErrors []*ParseError
+	}{
+		{
+			name:       "Validate Whole Table",
+			opts:       nil,
+			wantRoutes: 6,
+			wantErrors: []*ParseError{
+				{File: "table.txt", Line: 7, Text: "bad line", Err: ErrInvalidTablePrefix},
+				{File: "table.txt", Line: 8, Text: "10.0.0.0/33 100", Err: ErrInvalidTablePrefix},
+				{File: "table.txt", Line: 9, Text: "10.0.0.0/8 notanasn", Err: ErrInvalidTableASN},
+			},
+		},
+		{
+			name:       "Validate Until First Bad Line",
+			opts:       []Option{WithStrict()},
+			wantRoutes: 6,
+			wantErrors: []*ParseError{
+				{File: "table.txt", Line: 7, Text: "bad line", Err: ErrInvalidTablePrefix},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got, err := ValidateTable(strings.NewReader(tableTestText), "table.txt", testCase.opts...)
+		if err != nil {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+		}
+
+		if got.Routes != testCase.wantRoutes || reflect.DeepEqual(got.Errors, testCase.wantErrors) != true {
+			t.Fatalf("%s: result does not match: got %v %v, want %v %v", testCase.name, got.Routes, got.Errors, testCase.wantRoutes, testCase.wantErrors)
+		}
+	}
+
+	want := `table.txt:2: Wrong number of fields in routing table line: "8.0.0.0/9"`
+	if got := (&ParseError{File: "table.txt", Line: 2, Text: "8.0.0.0/9", Err: ErrInvalidTableLine}).Error(); got != want {
+		t.Fatalf("result does not match: got %v, want %v", got, want)
+	}
+}
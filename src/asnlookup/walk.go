@@ -0,0 +1,117 @@
+package asnlookup
+
+// WalkFunc is called by Walk and WalkPrefix for every route in the trie
+// with the route prefix in CIDR format (8.8.8.0/24). Returning false stops
+// the walk.
+type WalkFunc func(prefix string, info NodeInfo) bool
+
+// Walk calls "fn" for every route in the trie in address order, shorter
+// prefix first for same address. IPv4 routes come before IPv6 routes. It
+// returns false if "fn" stopped the walk.
+func (t *Trie) Walk(fn WalkFunc) bool {
+	return t.Root4.walk(fn) && t.Root6.walk(fn)
+}
+
+// WalkPrefix calls "fn" like Walk, but only for routes with prefix of "ip"
+// and routes inside it
+func (t *Trie) WalkPrefix(ip IPAddress, fn WalkFunc) bool {
+	node := t.root(ip)
+	for i := 1; i <= ip.GetCidrLen() && node != nil; i++ {
+		if ip.GetNthHighestBit(uint8(i)) == 0 {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+
+	return node.walk(fn)
+}
+
+// walk calls "fn" for routes of "n" and all nodes below it in pre-order,
+// which is address order
+func (n *Node) walk(fn WalkFunc) bool {
+	if n == nil {
+		return true
+	}
+
+	for _, info := range n.Info {
+		if !fn(info.Prefix(), info) {
+			return false
+		}
+	}
+
+	return n.Left.walk(fn) && n.Right.walk(fn)
+}
+
+// Walk calls "fn" for every route in the trie in address order, shorter
+// prefix first for same address. IPv4 routes come before IPv6 routes. It
+// returns false if "fn" stopped the walk.
+func (t *RadixTrie) Walk(fn WalkFunc) bool {
+	return t.Root4.walk(fn) && t.Root6.walk(fn)
+}
+
+// WalkPrefix calls "fn" like Walk, but only for routes with prefix of "ip"
+// and routes inside it
+func (t *RadixTrie) WalkPrefix(ip IPAddress, fn WalkFunc) bool {
+	key := ipKey(ip)
+	length := ip.GetCidrLen()
+	node := t.root(ip)
+
+	// Walk down to first node with prefix inside "ip" prefix
+	for node != nil && node.length < length {
+		child := node.Left
+		if keyBit(key, node.length+1) == 1 {
+			child = node.Right
+		}
+
+		if child != nil {
+			common := minInt(length, child.length)
+			if commonPrefixLen(key, child.key, common) != common {
+				child = nil
+			}
+		}
+		node = child
+	}
+
+	return node.walk(fn)
+}
+
+// walk calls "fn" for routes of "n" and all nodes below it in pre-order,
+// which is address order
+func (n *RadixNode) walk(fn WalkFunc) bool {
+	if n == nil {
+		return true
+	}
+
+	for _, info := range n.Info {
+		if !fn(info.Prefix(), info) {
+			return false
+		}
+	}
+
+	return n.Left.walk(fn) && n.Right.walk(fn)
+}
+
+// Walk calls "fn" for every route of the table like RouteTrie Walk. Table
+// is locked for reading during the walk, so "fn" must not call methods of
+// the table.
+func (t *Table) Walk(fn WalkFunc) {
+	data := t.rlock()
+	defer data.mu.RUnlock()
+	data.trie.Walk(fn)
+}
+
+// WalkPrefix calls "fn" like Walk, but only for routes with "prefix" and
+// routes inside it. "prefix" is an address or a prefix in CIDR format. It
+// returns ErrInvalidInputIPAddress if "prefix" is not valid.
+func (t *Table) WalkPrefix(prefix string, fn WalkFunc) error {
+	ipToFind, err := newIPToFind(prefix)
+	if err != nil {
+		return err
+	}
+
+	data := t.rlock()
+	defer data.mu.RUnlock()
+	data.trie.WalkPrefix(ipToFind, fn)
+	return nil
+}
@@ -0,0 +1,228 @@
+package asnlookup
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	routes := []ipCidrAsn{
+		{"2604:a880:2:d0::/64", 14061},
+		{"8.8.8.0/24", 15169},
+		{"8.0.0.0/8", 3356},
+		{"1.1.1.0/24", 13335},
+		{"8.8.4.0/24", 15169},
+		{"8.0.0.0/9", 3356},
+		{"8.8.8.0/24", 350},
+		{"2604:a880::/32", 14061},
+	}
+
+	testCases := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{
+			name: "Walk All Routes",
+			want: []string{
+				"1.1.1.0/24", "8.0.0.0/8", "8.0.0.0/9", "8.8.4.0/24",
+				"8.8.8.0/24", "8.8.8.0/24", "2604:a880::/32", "2604:a880:2:d0::/64",
+			},
+		},
+		{
+			name:  "Stop Early",
+			limit: 3,
+			want:  []string{"1.1.1.0/24", "8.0.0.0/8", "8.0.0.0/9"},
+		},
+		{
+			name:   "Walk Subtree",
+			prefix: "8.8.0.0/16",
+			want:   []string{"8.8.4.0/24", "8.8.8.0/24", "8.8.8.0/24"},
+		},
+		{
+			name:   "Walk Subtree Including Prefix",
+			prefix: "8.0.0.0/9",
+			want:   []string{"8.0.0.0/9", "8.8.4.0/24", "8.8.8.0/24", "8.8.8.0/24"},
+		},
+		{
+			name:   "Walk Subtree Stop Early",
+			prefix: "8.0.0.0/8",
+			limit:  2,
+			want:   []string{"8.0.0.0/8", "8.0.0.0/9"},
+		},
+		{
+			name:   "Walk IPv6 Subtree",
+			prefix: "2604:a880::/32",
+			want:   []string{"2604:a880::/32", "2604:a880:2:d0::/64"},
+		},
+		{
+			name:   "Walk Empty Subtree",
+			prefix: "9.0.0.0/8",
+			want:   []string{},
+		},
+		{
+			name:   "Walk Host Prefix",
+			prefix: "8.8.8.8/32",
+			want:   []string{},
+		},
+	}
+
+	for _, newTrie := range []func() RouteTrie{newTestTrie, newTestRadixTrie} {
+		trie := buildTestTrie(t, newTrie, routes)
+		for _, testCase := range testCases {
+			got := []string{}
+			fn := func(prefix string, info NodeInfo) bool {
+				if prefix != info.Prefix() {
+					t.Fatalf("%s: prefix does not match: got %v, want %v", testCase.name, prefix, info.Prefix())
+				}
+
+				got = append(got, prefix)
+				return testCase.limit == 0 || len(got) < testCase.limit
+			}
+
+			var completed bool
+			if testCase.prefix == "" {
+				completed = trie.Walk(fn)
+			} else {
+				ipAddress, err := newTestIPAddress(testCase.prefix, -1)
+				if err != nil {
+					t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, nil)
+				}
+				completed = trie.WalkPrefix(ipAddress, fn)
+			}
+
+			if reflect.DeepEqual(got, testCase.want) != true {
+				t.Fatalf("%s: walked routes do not match: got %v, want %v", testCase.name, got, testCase.want)
+			}
+			if completed != (testCase.limit == 0) {
+				t.Fatalf("%s: completed does not match: got %v, want %v", testCase.name, completed, testCase.limit == 0)
+			}
+		}
+	}
+}
+
+// TestTableWalk only uses exported API, as users of the package do
+func TestTableWalk(t *testing.T) {
+	table, err := NewTable(strings.NewReader(`8.8.8.0/24 15169
+8.0.0.0/8 3356
+1.1.1.0/24 13335
+8.8.4.0/24 15169
+2604:a880::/32 14061
+`))
+	if err != nil {
+		t.Fatalf("received error does not match: got %v, want %v", err, nil)
+	}
+
+	testCases := []struct {
+		name    string
+		prefix  string
+		limit   int
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "Walk All Routes",
+			want: []string{"1.1.1.0/24 13335", "8.0.0.0/8 3356", "8.8.4.0/24 15169", "8.8.8.0/24 15169", "2604:a880::/32 14061"},
+		},
+		{
+			name:  "Stop Early",
+			limit: 2,
+			want:  []string{"1.1.1.0/24 13335", "8.0.0.0/8 3356"},
+		},
+		{
+			name:   "Walk Prefix",
+			prefix: "8.8.0.0/16",
+			want:   []string{"8.8.4.0/24 15169", "8.8.8.0/24 15169"},
+		},
+		{
+			name:   "Walk Address",
+			prefix: "8.8.8.8",
+			want:   []string{},
+		},
+		{
+			name:    "Walk Invalid Prefix",
+			prefix:  "8.8.8.0/33",
+			want:    []string{},
+			wantErr: ErrInvalidInputIPAddress,
+		},
+	}
+
+	for _, testCase := range testCases {
+		got := []string{}
+		fn := func(prefix string, info NodeInfo) bool {
+			got = append(got, prefix+" "+info.Origin())
+			return testCase.limit == 0 || len(got) < testCase.limit
+		}
+
+		if testCase.prefix == "" {
+			table.Walk(fn)
+		} else if err := table.WalkPrefix(testCase.prefix, fn); err != testCase.wantErr {
+			t.Fatalf("%s: received error does not match: got %v, want %v", testCase.name, err, testCase.wantErr)
+		}
+
+		if reflect.DeepEqual(got, testCase.want) != true {
+			t.Fatalf("%s: walked routes do not match: got %v, want %v", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestWalkEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	routes := []ipCidrAsn{}
+	for i, route := range append(randomIPv4Cidrs(r, 2000), randomIPv6Cidrs(r, 2000)...) {
+		routes = append(routes, ipCidrAsn{route, i})
+	}
+	binaryTrie := buildTestTrie(t, newTestTrie, routes)
+	radixTrie := buildTestTrie(t, newTestRadixTrie, routes)
+
+	walk := func(trie RouteTrie, ip IPAddress) NodeInfoList {
+		infoList := NodeInfoList{}
+		fn := func(prefix string, info NodeInfo) bool {
+			infoList = append(infoList, info)
+			return true
+		}
+
+		if ip == nil {
+			trie.Walk(fn)
+		} else {
+			trie.WalkPrefix(ip, fn)
+		}
+		return infoList
+	}
+
+	want := walk(binaryTrie, nil)
+	if got := walk(radixTrie, nil); reflect.DeepEqual(got, want) != true {
+		t.Fatalf("walked routes do not match")
+	}
+
+	// Walk of a prefix is route with same prefix followed by covered routes
+	// for a sample of prefixes, as short prefixes cover most of the trie
+	targets := append(randomIPv4Cidrs(r, 150), randomIPv6Cidrs(r, 150)...)
+	for i := 0; i < len(routes); i += 40 {
+		targets = append(targets, routes[i].ip)
+	}
+
+	for _, target := range targets {
+		prefix, err := newTestIPAddress(target, -1)
+		if err != nil {
+			t.Fatalf("received error for %s does not match: got %v, want %v", target, err, nil)
+		}
+
+		want := NodeInfoList{}
+		for _, info := range binaryTrie.Find(prefix) {
+			if info.Cidr == prefix.GetCidrLen() {
+				want = append(want, info)
+			}
+		}
+		want = append(want, binaryTrie.FindCovered(prefix)...)
+
+		for _, trie := range []RouteTrie{binaryTrie, radixTrie} {
+			if got := walk(trie, prefix); reflect.DeepEqual(got, want) != true {
+				t.Fatalf("%s: walked routes do not match: got %v, want %v", target, got, want)
+			}
+		}
+	}
+}
@@ -2,26 +2,205 @@ package main
 
 import (
 	"asnlookup"
+	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
 func main() {
 
-	// Get the configuration
-	cfg, err := asnlookup.GetConfig()
+	// Subcommands are handled before flags of lookup mode are parsed
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "prefixes":
+			os.Exit(runPrefixes(os.Args[2:]))
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		}
+	}
+
+	batch := flag.Bool("batch", false, "read target IP addresses from stdin, one per line")
+	input := flag.String("input", "", "read target IP addresses from `file`, one per line (implies -batch)")
+	format := flag.String("format", asnlookup.FormatText, "output `format`: text, json, ndjson or csv")
+	notation := flag.String("asn-notation", string(asnlookup.ASPlain), "ASN `notation` in text and csv output: asplain, asdot or asdot+")
+	expandIPv6 := flag.Bool("ipv6-expanded", false, "print IPv6 prefixes in uncompressed format instead of RFC 5952 format")
+	covering := flag.Bool("covering", false, "return routes covering target address or prefix (default)")
+	covered := flag.Bool("covered", false, "return routes more specific than target prefix")
+	exact := flag.Bool("exact", false, "return routes with exactly the target prefix")
+	longest := flag.Bool("longest", false, "return only the most specific route covering target")
+	tf := &tableFlags{}
+	tf.register(flag.CommandLine)
+	flag.Parse()
+
+	mode, ok := queryMode(*covering, *covered, *exact)
+	if !ok {
+		fmt.Printf("Error: Please use only one of -covering, -covered and -exact\n")
+		os.Exit(1)
+	}
+	if *longest && mode != asnlookup.QueryCovering {
+		fmt.Printf("Error: -longest can not be used with -covered or -exact\n")
+		os.Exit(1)
+	}
+	lf := &lookupFlags{mode: mode, longest: *longest}
+
+	isBatch := *batch || *input != ""
+	writerOpts := []asnlookup.WriterOption{asnlookup.WithASNotation(asnlookup.ASNotation(*notation))}
+	if *expandIPv6 {
+		writerOpts = append(writerOpts, asnlookup.WithExpandedIPv6())
+	}
+	w, err := asnlookup.NewResultWriter(os.Stdout, *format, isBatch, writerOpts...)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if isBatch {
+		os.Exit(runBatch(*input, lf, w, tf))
+	}
+
+	if flag.NArg() == 0 {
+		fmt.Printf("Error: %s\n", asnlookup.ErrNoIPToFind)
+		os.Exit(1)
+	} else if flag.NArg() > 1 {
+		fmt.Printf("Error: %s\n", asnlookup.ErrMoreThanOneIPToFind)
+		os.Exit(1)
+	}
+
+	table, err := tf.loadTable()
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		os.Exit(1)
 	}
 
 	// Do a lookup
-	nodeInfoList := asnlookup.Find(cfg)
-	if len(nodeInfoList) == 0 {
+	result, err := lf.query(table, flag.Arg(0))
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
 		os.Exit(1)
 	}
 
-	for _, info := range nodeInfoList {
-		fmt.Printf("%s/%d %d\n", info.Subnet, info.Cidr, info.Asn)
+	err = w.Write(result)
+	if err == nil {
+		err = w.Flush()
 	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(result.Matches) == 0 {
+		os.Exit(1)
+	}
+}
+
+// queryMode returns query mode selected by -covering, -covered and -exact
+// flags. It returns false if more than one of them is set.
+func queryMode(covering, covered, exact bool) (asnlookup.QueryMode, bool) {
+	mode := asnlookup.QueryCovering
+	count := 0
+	for _, m := range []struct {
+		set  bool
+		mode asnlookup.QueryMode
+	}{
+		{covering, asnlookup.QueryCovering},
+		{covered, asnlookup.QueryCovered},
+		{exact, asnlookup.QueryExact},
+	} {
+		if m.set {
+			mode = m.mode
+			count++
+		}
+	}
+
+	return mode, count <= 1
+}
+
+// lookupFlags holds command line flags which select what is looked up
+// for each target
+type lookupFlags struct {
+	mode    asnlookup.QueryMode
+	longest bool
+}
+
+// query looks up "target" in "table" as selected by flags. With -longest
+// only the first longest match is kept, which is match of embedded IPv4
+// address with -translate-ipv4. Notes of the query are dropped, so text
+// output is a single line.
+func (lf *lookupFlags) query(table *asnlookup.Table, target string) (asnlookup.Result, error) {
+	result, err := table.QueryPrefix(target, lf.mode)
+	if err != nil || !lf.longest {
+		return result, err
+	}
+
+	matches := []asnlookup.Match{}
+	if len(result.Matches) > 0 && result.Matches[0].Longest {
+		matches = append(matches, result.Matches[0])
+	}
+
+	result.Special = nil
+	result.Translation = nil
+	result.Matches = matches
+	return result, nil
+}
+
+// runBatch loads the table once and looks up every target IP address or
+// prefix read from input file (or stdin if file name is empty) as selected
+// by "lf". Malformed lines are reported on stderr and skipped. It returns
+// process exit code.
+func runBatch(inputFile string, lf *lookupFlags, w asnlookup.ResultWriter, tf *tableFlags) int {
+	var reader io.Reader = os.Stdin
+	if inputFile != "" {
+		file, err := os.Open(inputFile)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return 1
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	table, err := tf.loadTable()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+
+	lineNum := 0
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lineNum++
+		ipStr := strings.TrimSpace(scanner.Text())
+		if ipStr == "" {
+			continue
+		}
+
+		result, err := lf.query(table, ipStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: line %d: %s: %s\n", lineNum, ipStr, err)
+			continue
+		}
+
+		// Write one result per target IP address
+		if err := w.Write(result); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return 1
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+
+	return 0
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"asnlookup"
+	"flag"
+	"fmt"
+)
+
+// runPrefixes implements "asnlookup prefixes <asn>" subcommand. It lists
+// prefixes originated by ASN sorted by address, followed by number of
+// prefixes and address space covered for each address type.
+func runPrefixes(args []string) int {
+	fs := flag.NewFlagSet("prefixes", flag.ExitOnError)
+	expandIPv6 := fs.Bool("ipv6-expanded", false, "print IPv6 prefixes in uncompressed format instead of RFC 5952 format")
+	tf := &tableFlags{}
+	tf.register(fs)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Printf("Error: Please provide one ASN, e.g. asnlookup prefixes AS15169\n")
+		return 1
+	}
+
+	// ASN can be given with or without "AS" prefix, in asplain or asdot
+	asn, err := asnlookup.ParseASN(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Error: Invalid ASN %s\n", fs.Arg(0))
+		return 1
+	}
+
+	table, err := tf.loadTable()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+
+	prefixes := table.Prefixes(asn)
+	for _, ip := range prefixes {
+		subnet := ip.GetString()
+		if *expandIPv6 {
+			subnet = asnlookup.ExpandIPv6(subnet)
+		}
+		fmt.Printf("%s/%d\n", subnet, ip.GetCidrLen())
+	}
+
+	summary := prefixes.Summary()
+	fmt.Printf("IPv4: %d prefixes, %s addresses\n", summary.IPv4Prefixes, summary.IPv4Addresses)
+	fmt.Printf("IPv6: %d prefixes, %s addresses\n", summary.IPv6Prefixes, summary.IPv6Addresses)
+
+	if len(prefixes) == 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"asnlookup"
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runServe implements "asnlookup serve" subcommand. It starts HTTP server
+// right away and loads the table in background. Server reports ready on
+// /readyz once table is loaded. Table is reloaded every -reload interval
+// and on SIGHUP.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	tf := &tableFlags{}
+	tf.register(fs)
+	listen := fs.String("listen", ":8080", "`address` to listen on")
+	reload := fs.Duration("reload", 0, "reload table every `interval`, e.g. 1h (0 disables periodic reload)")
+	fs.Parse(args)
+
+	server := asnlookup.NewServer()
+
+	// Forward SIGHUP to table reload trigger
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	trigger := make(chan struct{})
+	go func() {
+		for range hup {
+			trigger <- struct{}{}
+		}
+	}()
+
+	go func() {
+		table, err := tf.loadTable()
+		if err != nil {
+			log.Printf("Error: loading table: %s", err)
+			os.Exit(1)
+		}
+
+		server.SetTable(table)
+		log.Printf("Table loaded with %d routes, ready to serve lookups", table.Status().Routes)
+
+		table.AutoReload(context.Background(), *reload, trigger, func(err error) {
+			if err != nil {
+				log.Printf("Error: reloading table, keeping table loaded %s ago: %s",
+					table.Status().Age().Round(time.Second), err)
+				return
+			}
+			log.Printf("Table reloaded with %d routes", table.Status().Routes)
+		})
+	}()
+
+	log.Printf("Listening on %s", *listen)
+	if err := http.ListenAndServe(*listen, server); err != nil {
+		log.Printf("Error: %s", err)
+		return 1
+	}
+
+	return 0
+}
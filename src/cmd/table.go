@@ -0,0 +1,102 @@
+package main
+
+import (
+	"asnlookup"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// tableFlags holds command line flags which control how routing table
+// is loaded. They are shared by lookup mode and all subcommands.
+type tableFlags struct {
+	format         string
+	strict         bool
+	cacheDir       string
+	cacheMaxAge    time.Duration
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	retries        int
+	translateIPv4  bool
+	insertPolicy   string
+}
+
+// register adds table flags to flag set "fs"
+func (tf *tableFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&tf.format, "table-format", string(asnlookup.TableFormatText), "routing table `format`: text, pfx2as or mrt")
+	fs.BoolVar(&tf.strict, "strict", false, "fail on first routing table line which can not be parsed")
+	fs.StringVar(&tf.insertPolicy, "insert-policy", string(asnlookup.InsertKeepAll), "how routes with same prefix are stored: keep-all, keep-first or replace")
+
+	cacheDir, _ := asnlookup.DefaultCacheDir()
+	fs.StringVar(&tf.cacheDir, "cache-dir", cacheDir, "`directory` to cache fetched table in (empty disables cache)")
+	fs.DurationVar(&tf.cacheMaxAge, "cache-max-age", asnlookup.DefaultCacheMaxAge, "use cached table without revalidating it for `duration`")
+	fs.DurationVar(&tf.connectTimeout, "connect-timeout", asnlookup.DefaultConnectTimeout, "`timeout` for connecting to table server")
+	fs.DurationVar(&tf.readTimeout, "read-timeout", asnlookup.DefaultReadTimeout, "`timeout` for table server sending no data")
+	fs.IntVar(&tf.retries, "retries", asnlookup.DefaultRetries, "`number` of times failed table download is retried")
+	fs.BoolVar(&tf.translateIPv4, "translate-ipv4", false, "also look up IPv4 address embedded in IPv4-mapped and NAT64 (64:ff9b::/96) addresses")
+}
+
+// options returns table options selected by flags
+func (tf *tableFlags) options() []asnlookup.Option {
+	opts := []asnlookup.Option{
+		asnlookup.WithTableFormat(asnlookup.TableFormat(tf.format)),
+		asnlookup.WithInsertPolicy(asnlookup.InsertPolicy(tf.insertPolicy)),
+		asnlookup.WithCacheDir(tf.cacheDir),
+		asnlookup.WithCacheMaxAge(tf.cacheMaxAge),
+		asnlookup.WithTimeouts(tf.connectTimeout, tf.readTimeout),
+		asnlookup.WithRetries(tf.retries, asnlookup.DefaultRetryBackoff),
+		asnlookup.WithWarningFunc(func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+		}),
+	}
+
+	if tf.strict {
+		opts = append(opts, asnlookup.WithStrict())
+	}
+	if tf.translateIPv4 {
+		opts = append(opts, asnlookup.WithIPv4Translation())
+	}
+
+	return opts
+}
+
+// loadTable loads routing table from file named by CONFIG_FILE_PATH
+// environment variable if defined. Otherwise it fetches table from
+// default URL. Lines skipped because they could not be parsed are
+// reported on stderr.
+func (tf *tableFlags) loadTable() (*asnlookup.Table, error) {
+	var table *asnlookup.Table
+	var err error
+	configFile := os.Getenv("CONFIG_FILE_PATH")
+	if configFile != "" {
+		table, err = asnlookup.LoadTable(configFile, tf.options()...)
+	} else {
+		table, err = asnlookup.FetchTable(asnlookup.DefaultTableURL, tf.options()...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := table.Status()
+	reportSkipped(status.Skipped)
+	if status.ASSetRoutes > 0 {
+		fmt.Fprintf(os.Stderr, "Note: %d routes of routing table have AS_SET origin\n", status.ASSetRoutes)
+	}
+	return table, nil
+}
+
+// reportSkipped prints number of skipped routing table entries by reason
+// on stderr
+func reportSkipped(skipped map[string]int) {
+	reasons := []string{}
+	for reason := range skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %d entries of routing table: %s\n", skipped[reason], reason)
+	}
+}
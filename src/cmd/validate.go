@@ -0,0 +1,48 @@
+package main
+
+import (
+	"asnlookup"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runValidate implements "asnlookup validate <file>" subcommand. It only
+// checks routing table file and prints every line which can not be
+// parsed with line number and reason. With -strict it stops at first bad
+// line. It returns 1 if any line can not be parsed.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	tf := &tableFlags{}
+	tf.register(fs)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Printf("Error: Please provide one routing table file, e.g. asnlookup validate table.txt\n")
+		return 1
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	result, err := asnlookup.ValidateTable(file, fs.Arg(0), tf.options()...)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+
+	for _, pe := range result.Errors {
+		fmt.Println(pe)
+	}
+	fmt.Printf("%d routes (%d with AS_SET origin), %d bad lines\n", result.Routes, result.ASSetRoutes, len(result.Errors))
+
+	if len(result.Errors) > 0 {
+		return 1
+	}
+
+	return 0
+}